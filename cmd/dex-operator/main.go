@@ -27,6 +27,7 @@ import (
 	"github.com/kubic-project/dex-operator/pkg/apis"
 	dexcfg "github.com/kubic-project/dex-operator/pkg/config"
 	"github.com/kubic-project/dex-operator/pkg/controller"
+	"github.com/kubic-project/dex-operator/pkg/webhook"
 	"github.com/renstrom/dedent"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -77,6 +78,10 @@ func newCmdManager(out io.Writer) *cobra.Command {
 			err = controller.AddToManager(mgr)
 			kubeadmutil.CheckErr(err)
 
+			glog.V(1).Infof("[kubic] setting up the webhooks")
+			err = webhook.AddToManager(mgr)
+			kubeadmutil.CheckErr(err)
+
 			glog.V(1).Infof("[kubic] starting the controller")
 			err = mgr.Start(signals.SetupSignalHandler())
 			kubeadmutil.CheckErr(err)
@@ -86,7 +91,10 @@ func newCmdManager(out io.Writer) *cobra.Command {
 	flagSet := cmd.PersistentFlags()
 	flagSet.StringVar(&kubeconfigFile, "kubeconfig", "", "Use this kubeconfig file for talking to the API server (not necessary when running in the kuberentes cluster).")
 	flagSet.StringVar(&dexcfg.DefaultPrefix, "prefix", dexcfg.DefaultPrefix, "A prefix for all the resources created by the operator.")
+	flagSet.StringVar(&dexcfg.DefaultNamespace, "namespace", dexcfg.DefaultNamespace, "Default namespace where Dex is run.")
 	flagSet.IntVar(&dexcfg.DefaultDeployNumReplicas, "replicas", dexcfg.DefaultDeployNumReplicas, "Default number of replicas in the Dex Deployment.")
+	flagSet.BoolVar(&dexcfg.WebhooksEnabled, "webhooks", dexcfg.WebhooksEnabled, "Run the admission webhooks server for validating the Custom Resources.")
+	flagSet.IntVar(&dexcfg.DefaultWebhookPort, "webhook-port", dexcfg.DefaultWebhookPort, "Port for the admission webhooks server.")
 
 	return cmd
 }
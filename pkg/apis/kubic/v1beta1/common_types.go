@@ -0,0 +1,133 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SecretKeyReference points to a key in a Secret, possibly in a different namespace
+type SecretKeyReference struct {
+	// Name of the Secret
+	Name string `json:"name,omitempty"`
+
+	// Namespace of the Secret. Default: the namespace where Dex is run
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// The key in the Secret. Every connector provides a sensible default.
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// ObjectKeyReference points to a key in a ConfigMap or in a Secret
+type ObjectKeyReference struct {
+	// Kind of the object: "ConfigMap" (default) or "Secret"
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Name of the object
+	Name string `json:"name,omitempty"`
+
+	// Namespace of the object. Default: the namespace where Dex is run
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// The key in the object. Every connector provides a sensible default.
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// ConditionType is the type of a condition
+type ConditionType string
+
+// Condition describes the state of an object at a certain point
+type Condition struct {
+	// Type of the condition
+	Type ConditionType `json:"type"`
+
+	// Status of the condition: True, False or Unknown
+	Status corev1.ConditionStatus `json:"status"`
+
+	// The last time the condition transitioned from one status to another
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// The reason for the condition's last transition
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// A human readable message indicating details about the transition
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+const (
+	// ConnectorReady means the connector has been rendered in the Dex configuration
+	ConnectorReady ConditionType = "Ready"
+
+	// ConnectorInvalid means the connector has been ignored because it is not valid
+	ConnectorInvalid ConditionType = "Invalid"
+
+	// ConnectorRejected means the connector has been removed from the Dex configuration
+	// because it broke the rollout of Dex
+	ConnectorRejected ConditionType = "Rejected"
+)
+
+// ConnectorInstanceStatus is the state of a connector in one of the DexConfigurations selecting it
+type ConnectorInstanceStatus struct {
+	// The name of the DexConfiguration
+	Name string `json:"name"`
+
+	// The hash of the Dex configuration (of this DexConfiguration) where this connector was included
+	// +optional
+	ConfigHash string `json:"configHash,omitempty"`
+
+	// The reason why the connector has been ignored by this DexConfiguration
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// A message explaining why the connector has been ignored by this DexConfiguration
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ConnectorStatus is the observed state of a connector, common to all the kinds of connectors
+type ConnectorStatus struct {
+	// Current conditions of the connector (Ready, Invalid, Rejected), summarizing its
+	// state in all the DexConfigurations selecting it
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// The ID of the connector in the Dex configuration
+	// +optional
+	ConnectorID string `json:"connectorID,omitempty"`
+
+	// The generation of the connector observed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// A message explaining why the connector has been ignored
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// The state of the connector in each of the DexConfigurations selecting it
+	// +optional
+	Instances []ConnectorInstanceStatus `json:"instances,omitempty"`
+}
@@ -34,6 +34,101 @@ type DexStaticClient struct {
 	Public bool `json:"public,omitempty"`
 }
 
+// DexExposeMode is the way the Dex service is exposed
+type DexExposeMode string
+
+const (
+	// DexExposeNodePort exposes Dex with a NodePort Service
+	DexExposeNodePort DexExposeMode = "NodePort"
+
+	// DexExposeLoadBalancer exposes Dex with a LoadBalancer Service
+	DexExposeLoadBalancer DexExposeMode = "LoadBalancer"
+
+	// DexExposeIngress exposes Dex with a ClusterIP Service and an Ingress
+	DexExposeIngress DexExposeMode = "Ingress"
+)
+
+// DexIngressSpec describes the Ingress used for exposing Dex
+type DexIngressSpec struct {
+	// The host name used in the Ingress rule (and in the issuer)
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// Name of the Secret (in the namespace where Dex is run) with the certificate
+	// used by the Ingress controller for TLS
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+
+	// The Ingress class (set in the "kubernetes.io/ingress.class" annotation)
+	// +optional
+	Class string `json:"class,omitempty"`
+
+	// Annotations for the Ingress
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// DexExposeSpec describes how Dex is exposed outside the cluster
+type DexExposeSpec struct {
+	// The way Dex is exposed: "NodePort" (default), "LoadBalancer" or
+	// "Ingress" (a ClusterIP Service with an Ingress)
+	// +optional
+	Mode DexExposeMode `json:"mode,omitempty"`
+
+	// Annotations for the Service (ie, for configuring the LoadBalancer)
+	// +optional
+	ServiceAnnotations map[string]string `json:"serviceAnnotations,omitempty"`
+
+	// The IP requested for the LoadBalancer
+	// +optional
+	LoadBalancerIP string `json:"loadBalancerIP,omitempty"`
+
+	// The Ingress (in the "Ingress" mode)
+	// +optional
+	Ingress DexIngressSpec `json:"ingress,omitempty"`
+}
+
+// DexCertificateMode is the way the certificate for Dex is obtained
+type DexCertificateMode string
+
+const (
+	// DexCertificateCSR generates a certificate signed by the Kubernetes CA (with a CSR)
+	DexCertificateCSR DexCertificateMode = "CSR"
+
+	// DexCertificateCertManager requests the certificate to cert-manager
+	DexCertificateCertManager DexCertificateMode = "CertManager"
+)
+
+// DexCertManagerIssuerRef is a reference to a cert-manager issuer
+type DexCertManagerIssuerRef struct {
+	// Name of the issuer
+	Name string `json:"name,omitempty"`
+
+	// Kind of issuer: "Issuer" (default, in the namespace where Dex is run) or "ClusterIssuer"
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Group of the issuer ("cert-manager.io" by default)
+	// +optional
+	Group string `json:"group,omitempty"`
+}
+
+// DexCertManagerSpec describes the cert-manager Certificate requested for Dex
+type DexCertManagerSpec struct {
+	// The issuer of the certificate
+	// +optional
+	IssuerRef DexCertManagerIssuerRef `json:"issuerRef,omitempty"`
+
+	// Duration of the certificate (the default duration of cert-manager is used when not specified)
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// Renew the certificate this time before it expires (the cert-manager default is used
+	// when not specified)
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+}
+
 // DexConfigurationSpec defines the desired state of DexConfiguration
 type DexConfigurationSpec struct {
 	// External FQDNs for the Dex service (for certificates)
@@ -41,27 +136,87 @@ type DexConfigurationSpec struct {
 	// +optional
 	Names []string `json:"names,omitempty"`
 
-	// the NodePort used y the Dex server
+	// The issuer URL (ie, "https://my-company.com/dex"), overriding the URL derived
+	// from the way Dex is exposed. It is useful when Dex runs behind a reverse proxy
+	// or under a path prefix.
+	// +optional
+	Issuer string `json:"issuer,omitempty"`
+
+	// Selector for the connectors used in this Dex instance
+	// All the connectors are used when it is not specified.
+	// +optional
+	ConnectorSelector *metav1.LabelSelector `json:"connectorSelector,omitempty"`
+
+	// The namespace where Dex (and all the objects it needs) is run
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// the NodePort used y the Dex server. Default: 32000 for the main instance,
+	// or a port allocated by Kubernetes for any other instance
 	// +optional
 	NodePort int `json:"nodePort,omitempty"`
 
+	// How Dex is exposed outside the cluster
+	// +optional
+	Expose DexExposeSpec `json:"expose,omitempty"`
+
 	// The image used for Dex
 	// +optional
 	Image string `json:"image,omitempty"`
 
-	// number of replicas for the Dex deployment
+	// number of replicas for the Dex deployment (0 stops Dex)
 	// +optional
-	Replicas int `json:"replicas,omitempty"`
+	Replicas *int32 `json:"replicas,omitempty"`
 
 	// Static clients
 	// +optional
 	StaticClients []DexStaticClient `json:"staticClients,omitempty"`
 
+	// Maximum time (in seconds) for a rollout of Dex to make progress before it is
+	// considered failed
+	// +optional
+	ProgressDeadlineSeconds int `json:"progressDeadlineSeconds,omitempty"`
+
+	// Number of Dex configurations successfully rolled out that are kept for
+	// automatic rollbacks
+	// +optional
+	RevisionHistoryLimit int `json:"revisionHistoryLimit,omitempty"`
+
 	// Use an (already existing) certificate for the Dex service
 	// +optional
 	Certificate corev1.SecretReference `json:"certificate,omitempty"`
 
+	// The way the certificate is obtained (when no certificate is specified):
+	// "CSR" (default) or "CertManager"
+	// +optional
+	CertificateMode DexCertificateMode `json:"certificateMode,omitempty"`
+
+	// The cert-manager Certificate (in the "CertManager" mode)
+	// +optional
+	CertManager DexCertManagerSpec `json:"certManager,omitempty"`
+
+	// Renew the generated certificate when it expires in less than this number of days
+	// +optional
+	CertificateRenewBeforeDays int `json:"certificateRenewBeforeDays,omitempty"`
+
+	// Algorithm for the private key of the generated certificate: "RSA-2048", "RSA-3072",
+	// "RSA-4096", "ECDSA-P256", "ECDSA-P384" or "Ed25519"
+	// +optional
+	CertificateKeyAlgorithm string `json:"certificateKeyAlgorithm,omitempty"`
+
+	// Name of the signer for the generated certificate (required when the API server
+	// only supports the certificates.k8s.io/v1 API)
+	// +optional
+	CertificateSignerName string `json:"certificateSignerName,omitempty"`
+
+	// Kind of object where the Dex configuration is stored: "Secret" or "ConfigMap".
+	// New installations use a Secret by default, as the configuration contains some
+	// sensitive information, while existing installations keep using a ConfigMap.
+	// +optional
+	ConfigStorage string `json:"configStorage,omitempty"`
+
 	// TODO: maybe this should be a property of the LDAPConnector
+	// The group bound to the cluster-admin role. Only used by the main instance.
 	// +optional
 	AdminGroup string `json:"adminGroup,omitempty"`
 }
@@ -81,19 +236,137 @@ type DexStaticClientStatus struct {
 	Public bool `json:"public,omitempty"`
 }
 
+const (
+	// DexReady means Dex is running with the current configuration
+	DexReady ConditionType = "Ready"
+
+	// DexConfigRendered means the Dex configuration has been generated and stored
+	DexConfigRendered ConditionType = "ConfigRendered"
+
+	// DexCertificateReady means there is a valid certificate for the Dex service
+	DexCertificateReady ConditionType = "CertificateReady"
+
+	// DexDeploymentAvailable means the Dex Deployment has the minimum number of replicas available
+	DexDeploymentAvailable ConditionType = "DeploymentAvailable"
+
+	// DexProgressing means the rollout of the Dex Deployment is in progress or complete
+	// (it will be False when the rollout has not progressed in the ProgressDeadlineSeconds)
+	DexProgressing ConditionType = "Progressing"
+
+	// DexDegraded means the last reconciliation has failed
+	DexDegraded ConditionType = "Degraded"
+)
+
+// DexRolloutStatus is the status of the rollout of the Dex Deployment
+type DexRolloutStatus struct {
+	// Hash of the configuration being rolled out
+	// +optional
+	ConfigHash string `json:"configHash,omitempty"`
+
+	// Connectors in the configuration being rolled out
+	// +optional
+	Connectors []string `json:"connectors,omitempty"`
+
+	// Time when the rollout of this configuration started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Total number of Dex pods
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// Number of Dex pods running with the current configuration
+	// +optional
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+
+	// Number of Dex pods ready (ie, passing the readiness probe)
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// Number of Dex pods available
+	// +optional
+	AvailableReplicas int32 `json:"availableReplicas,omitempty"`
+}
+
+// DexConfigRevision is a Dex configuration that has been successfully rolled out
+type DexConfigRevision struct {
+	// Hash of the configuration
+	ConfigHash string `json:"configHash"`
+
+	// Connectors in the configuration
+	// +optional
+	Connectors []string `json:"connectors,omitempty"`
+
+	// Time when the rollout was completed
+	// +optional
+	Time metav1.Time `json:"time,omitempty"`
+}
+
 // DexConfigurationStatus defines the observed state of DexConfiguration
 type DexConfigurationStatus struct {
+	// Current conditions of the Dex configuration
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// The generation of the DexConfiguration observed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
 	// Config is the (maybe namespaced) name of the ConfigMap
 	Config string `json:"config,omitempty"`
 
+	// ConfigKind is the kind of object used for storing the config: ConfigMap or Secret
+	ConfigKind string `json:"configKind,omitempty"`
+
+	// The issuer URL used by Dex
+	// +optional
+	Issuer string `json:"issuer,omitempty"`
+
+	// The public address discovered for the issuer (when it is not specified
+	// in the names, and Dex is exposed with a NodePort)
+	// +optional
+	DiscoveredAddress string `json:"discoveredAddress,omitempty"`
+
+	// Namespace where Dex is currently running
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
 	// Current deployment
 	Deployment string `json:"deployment,omitempty"`
 
+	// Rollout status of the current deployment
+	// +optional
+	Rollout DexRolloutStatus `json:"rollout,omitempty"`
+
+	// Last configurations successfully rolled out (the most recent first)
+	// +optional
+	Revisions []DexConfigRevision `json:"revisions,omitempty"`
+
+	// Hashes of the configurations that failed to roll out and were rolled back
+	// +optional
+	FailedConfigHashes []string `json:"failedConfigHashes,omitempty"`
+
+	// Connectors selected by the connectorSelector and used in the configuration, as "Kind/name"
+	// +optional
+	Connectors []string `json:"connectors,omitempty"`
+
+	// Connectors (in a specific generation) rejected for breaking a rollout
+	// +optional
+	RejectedConnectors []string `json:"rejectedConnectors,omitempty"`
+
 	// GeneratedCertificate is the certificate automatically generated for the Dex service
 	// It will be empty when using the certificate provided in Spec.Certificate
 	// It will be automatically removed when removing the DexConfiguration
 	GeneratedCertificate corev1.SecretReference `json:"generatedCertificate,omitempty"`
 
+	// The cert-manager Certificate created for Dex
+	// +optional
+	CertManagerCertificate string `json:"certManagerCertificate,omitempty"`
+
+	// Expiration time of the certificate used by Dex
+	// +optional
+	CertificateNotAfter *metav1.Time `json:"certificateNotAfter,omitempty"`
+
 	// Status of the static clients
 	StaticClients []DexStaticClientStatus `json:"staticClients,omitempty"`
 
@@ -107,6 +380,7 @@ type DexConfigurationStatus struct {
 
 // DexConfiguration is the Schema for the dexconfigurations API
 // +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
 type DexConfiguration struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
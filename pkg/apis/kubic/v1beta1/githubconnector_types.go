@@ -0,0 +1,104 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// see https://github.com/dexidp/dex/blob/master/Documentation/connectors/github.md
+
+// GitHubOrgSpec is an organization (and, optionally, some teams in that organization)
+// users must be members of for being allowed to log in.
+type GitHubOrgSpec struct {
+	// Name of the organization
+	Name string `json:"name,omitempty"`
+
+	// Names of the teams in the organization. When provided, only
+	// users that are members of any of these teams will be allowed.
+	// +optional
+	Teams []string `json:"teams,omitempty"`
+}
+
+// GitHubConnectorSpec defines the desired state of GitHubConnector
+type GitHubConnectorSpec struct {
+	Name string `json:"name,omitempty"`
+
+	ID string `json:"id,omitempty"`
+
+	// The client ID of the OAuth application registered in GitHub.
+	ClientID string `json:"clientID,omitempty"`
+
+	// A reference to the Secret where the client secret of the OAuth
+	// application is stored. The default key is "clientSecret".
+	ClientSecretRef SecretKeyReference `json:"clientSecretRef,omitempty"`
+
+	// The callback URL registered in the OAuth application.
+	// Default: the Dex issuer followed by "/callback".
+	// +optional
+	RedirectURI string `json:"redirectURI,omitempty"`
+
+	// Only users that are members of these organizations (and teams) will be allowed.
+	// +optional
+	Orgs []GitHubOrgSpec `json:"orgs,omitempty"`
+
+	// Load all the teams the user is a member of as groups, even when `orgs` is used.
+	// +optional
+	LoadAllGroups bool `json:"loadAllGroups,omitempty"`
+
+	// The field of the team used as the group name: "name" (default), "slug" or "both".
+	// +optional
+	TeamNameField string `json:"teamNameField,omitempty"`
+}
+
+// GitHubConnectorStatus defines the observed state of GitHubConnector
+type GitHubConnectorStatus struct {
+	ConnectorStatus `json:",inline"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+
+// GitHubConnector is the Schema for the githubconnectors API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type="string",JSONPath=".status.connectorID"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type GitHubConnector struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GitHubConnectorSpec   `json:"spec,omitempty"`
+	Status GitHubConnectorStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+
+// GitHubConnectorList contains a list of GitHubConnector
+type GitHubConnectorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GitHubConnector `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GitHubConnector{}, &GitHubConnectorList{})
+}
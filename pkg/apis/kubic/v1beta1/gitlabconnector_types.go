@@ -0,0 +1,92 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// see https://github.com/dexidp/dex/blob/master/Documentation/connectors/gitlab.md
+
+// GitLabConnectorSpec defines the desired state of GitLabConnector
+type GitLabConnectorSpec struct {
+	Name string `json:"name,omitempty"`
+
+	ID string `json:"id,omitempty"`
+
+	// The URL of the GitLab instance. Default: "https://gitlab.com"
+	// +optional
+	BaseURL string `json:"baseURL,omitempty"`
+
+	// The application ID of the OAuth application registered in GitLab.
+	ClientID string `json:"clientID,omitempty"`
+
+	// A reference to the Secret where the secret of the OAuth
+	// application is stored. The default key is "clientSecret".
+	ClientSecretRef SecretKeyReference `json:"clientSecretRef,omitempty"`
+
+	// The callback URL registered in the OAuth application.
+	// Default: the Dex issuer followed by "/callback".
+	// +optional
+	RedirectURI string `json:"redirectURI,omitempty"`
+
+	// Only users that are members of these groups will be allowed.
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+
+	// Use the username as the user ID instead of the internal GitLab ID.
+	// +optional
+	UseLoginAsID bool `json:"useLoginAsID,omitempty"`
+}
+
+// GitLabConnectorStatus defines the observed state of GitLabConnector
+type GitLabConnectorStatus struct {
+	ConnectorStatus `json:",inline"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+
+// GitLabConnector is the Schema for the gitlabconnectors API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type="string",JSONPath=".status.connectorID"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type GitLabConnector struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GitLabConnectorSpec   `json:"spec,omitempty"`
+	Status GitLabConnectorStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+
+// GitLabConnectorList contains a list of GitLabConnector
+type GitLabConnectorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GitLabConnector `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GitLabConnector{}, &GitLabConnectorList{})
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// see https://github.com/dexidp/dex/blob/master/Documentation/connectors/google.md
+
+// GoogleConnectorSpec defines the desired state of GoogleConnector
+type GoogleConnectorSpec struct {
+	Name string `json:"name,omitempty"`
+
+	ID string `json:"id,omitempty"`
+
+	// The client ID of the OAuth credentials registered in Google.
+	ClientID string `json:"clientID,omitempty"`
+
+	// A reference to the Secret where the client secret is stored.
+	// The default key is "clientSecret".
+	ClientSecretRef SecretKeyReference `json:"clientSecretRef,omitempty"`
+
+	// The callback URL registered in the OAuth credentials.
+	// Default: the Dex issuer followed by "/callback".
+	// +optional
+	RedirectURI string `json:"redirectURI,omitempty"`
+
+	// Only users from these Google Workspace domains will be allowed.
+	// +optional
+	HostedDomains []string `json:"hostedDomains,omitempty"`
+
+	// A reference to the Secret where the JSON file of the service account
+	// used for fetching groups is stored. The default key is "service-account.json".
+	// +optional
+	ServiceAccountRef SecretKeyReference `json:"serviceAccountRef,omitempty"`
+
+	// The email of a Google Workspace admin to impersonate when fetching groups.
+	// +optional
+	AdminEmail string `json:"adminEmail,omitempty"`
+}
+
+// GoogleConnectorStatus defines the observed state of GoogleConnector
+type GoogleConnectorStatus struct {
+	ConnectorStatus `json:",inline"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+
+// GoogleConnector is the Schema for the googleconnectors API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type="string",JSONPath=".status.connectorID"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type GoogleConnector struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GoogleConnectorSpec   `json:"spec,omitempty"`
+	Status GoogleConnectorStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+
+// GoogleConnectorList contains a list of GoogleConnector
+type GoogleConnectorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GoogleConnector `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GoogleConnector{}, &GoogleConnectorList{})
+}
@@ -91,9 +91,17 @@ type LDAPConnectorSpec struct {
 	// bindPW: password
 	// +optional
 	BindDN string `json:"bindDn,omitempty"`
+	// Deprecated: the password is stored in clear in the connector and in the
+	// Dex ConfigMap. Use `bindPWSecretRef` instead.
 	// +optional
 	BindPW string `json:"bindPw,omitempty"`
 
+	// A reference to a Secret with the bind password (by default, in a key
+	// named "bindPW"). The password is provided to the Dex pods in an
+	// environment variable, so it is never written in the ConfigMap.
+	// +optional
+	BindPWSecretRef SecretKeyReference `json:"bindPWSecretRef,omitempty"`
+
 	// +optional
 	UsernamePrompt string `json:"usernamePrompt,omitempty"`
 
@@ -102,10 +110,16 @@ type LDAPConnectorSpec struct {
 	// +optional
 	StartTLS bool `json:"startTLS,omitempty"`
 
-	// Path to a trusted root certificate file. Default: use the host's root CA.
+	// A PEM-encoded trusted root certificate, provided inline.
 	// +optional
 	RootCAData string `json:"rootCAData,omitempty"`
 
+	// A reference to a ConfigMap or Secret key (by default, "ca.crt") with a
+	// PEM bundle of trusted root certificates (several CAs can be provided).
+	// It takes precedence over `rootCAData`.
+	// +optional
+	RootCARef ObjectKeyReference `json:"rootCARef,omitempty"`
+
 	// +optional
 	User LDAPUserSpec `json:"user,omitempty"`
 
@@ -115,6 +129,7 @@ type LDAPConnectorSpec struct {
 
 // LDAPConnectorStatus defines the observed state of LDAPConnector
 type LDAPConnectorStatus struct {
+	ConnectorStatus `json:",inline"`
 }
 
 // +genclient
@@ -123,6 +138,10 @@ type LDAPConnectorStatus struct {
 
 // LDAPConnector is the Schema for the ldapconnectors API
 // +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type="string",JSONPath=".status.connectorID"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 type LDAPConnector struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// see https://github.com/dexidp/dex/blob/master/Documentation/connectors/microsoft.md
+
+// MicrosoftConnectorSpec defines the desired state of MicrosoftConnector
+type MicrosoftConnectorSpec struct {
+	Name string `json:"name,omitempty"`
+
+	ID string `json:"id,omitempty"`
+
+	// The application ID registered in Azure AD.
+	ClientID string `json:"clientID,omitempty"`
+
+	// A reference to the Secret where the secret of the application
+	// is stored. The default key is "clientSecret".
+	ClientSecretRef SecretKeyReference `json:"clientSecretRef,omitempty"`
+
+	// The callback URL registered in the application.
+	// Default: the Dex issuer followed by "/callback".
+	// +optional
+	RedirectURI string `json:"redirectURI,omitempty"`
+
+	// Restrict the users to some tenant: "common" (default), "organizations",
+	// "consumers", or a tenant name/UUID.
+	// +optional
+	Tenant string `json:"tenant,omitempty"`
+
+	// Only users that are members of these groups will be allowed.
+	// Only supported with a specific tenant.
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+
+	// Only load the security groups of the user.
+	// +optional
+	OnlySecurityGroups bool `json:"onlySecurityGroups,omitempty"`
+
+	// Use the group "name" (default) or "id" for the groups.
+	// +optional
+	GroupNameFormat string `json:"groupNameFormat,omitempty"`
+}
+
+// MicrosoftConnectorStatus defines the observed state of MicrosoftConnector
+type MicrosoftConnectorStatus struct {
+	ConnectorStatus `json:",inline"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+
+// MicrosoftConnector is the Schema for the microsoftconnectors API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type="string",JSONPath=".status.connectorID"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type MicrosoftConnector struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MicrosoftConnectorSpec   `json:"spec,omitempty"`
+	Status MicrosoftConnectorStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+
+// MicrosoftConnectorList contains a list of MicrosoftConnector
+type MicrosoftConnectorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MicrosoftConnector `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MicrosoftConnector{}, &MicrosoftConnectorList{})
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// see https://github.com/dexidp/dex/blob/master/Documentation/connectors/oidc.md
+
+// OIDCClaimMappingSpec maps some standard claims to non-standard claims
+// returned by the upstream provider.
+type OIDCClaimMappingSpec struct {
+	// The claim used as the preferred username. Default: "preferred_username"
+	// +optional
+	PreferredUsernameKey string `json:"preferredUsername,omitempty"`
+
+	// The claim used as the email. Default: "email"
+	// +optional
+	EmailKey string `json:"email,omitempty"`
+
+	// The claim used as the groups. Default: "groups"
+	// +optional
+	GroupsKey string `json:"groups,omitempty"`
+}
+
+// OIDCConnectorSpec defines the desired state of OIDCConnector
+type OIDCConnectorSpec struct {
+	Name string `json:"name,omitempty"`
+
+	ID string `json:"id,omitempty"`
+
+	// The URL of the upstream provider (ie, "https://accounts.google.com").
+	// Dex will use OpenID Connect discovery for finding the endpoints.
+	Issuer string `json:"issuer,omitempty"`
+
+	// The client ID registered in the upstream provider.
+	ClientID string `json:"clientID,omitempty"`
+
+	// A reference to the Secret where the client secret is stored.
+	// The default key is "clientSecret".
+	ClientSecretRef SecretKeyReference `json:"clientSecretRef,omitempty"`
+
+	// The callback URL registered in the upstream provider.
+	// Default: the Dex issuer followed by "/callback".
+	// +optional
+	RedirectURI string `json:"redirectURI,omitempty"`
+
+	// List of additional scopes to request in the token response.
+	// Default: "profile" and "email"
+	// +optional
+	Scopes []string `json:"scopes,omitempty"`
+
+	// Some providers return claims without "email_verified": when enabled,
+	// the email will be considered as verified.
+	// +optional
+	InsecureSkipEmailVerified bool `json:"insecureSkipEmailVerified,omitempty"`
+
+	// Use the groups claim returned by the upstream provider.
+	// +optional
+	InsecureEnableGroups bool `json:"insecureEnableGroups,omitempty"`
+
+	// Query the UserInfo endpoint for additional claims.
+	// +optional
+	GetUserInfo bool `json:"getUserInfo,omitempty"`
+
+	// The claim used as the user ID. Default: "sub"
+	// +optional
+	UserIDKey string `json:"userIDKey,omitempty"`
+
+	// The claim used as the user name. Default: "name"
+	// +optional
+	UserNameKey string `json:"userNameKey,omitempty"`
+
+	// Mappings for non-standard claims
+	// +optional
+	ClaimMapping OIDCClaimMappingSpec `json:"claimMapping,omitempty"`
+}
+
+// OIDCConnectorStatus defines the observed state of OIDCConnector
+type OIDCConnectorStatus struct {
+	ConnectorStatus `json:",inline"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+
+// OIDCConnector is the Schema for the oidcconnectors API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type="string",JSONPath=".status.connectorID"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type OIDCConnector struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OIDCConnectorSpec   `json:"spec,omitempty"`
+	Status OIDCConnectorStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+
+// OIDCConnectorList contains a list of OIDCConnector
+type OIDCConnectorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OIDCConnector `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OIDCConnector{}, &OIDCConnectorList{})
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// see https://github.com/dexidp/dex/blob/master/Documentation/connectors/saml.md
+
+// SAMLConnectorSpec defines the desired state of SAMLConnector
+type SAMLConnectorSpec struct {
+	Name string `json:"name,omitempty"`
+
+	ID string `json:"id,omitempty"`
+
+	// SSO URL used for POST value.
+	SSOURL string `json:"ssoURL,omitempty"`
+
+	// A reference to the ConfigMap (or Secret) where the CA used for
+	// validating the signature of the SAML response is stored.
+	// The default key is "ca.crt".
+	CARef ObjectKeyReference `json:"caRef,omitempty"`
+
+	// Dex's callback URL. Default: the Dex issuer followed by "/callback".
+	// +optional
+	RedirectURI string `json:"redirectURI,omitempty"`
+
+	// Name of the attributes in the returned assertions to map to ID token claims.
+	UsernameAttr string `json:"usernameAttr,omitempty"`
+	EmailAttr    string `json:"emailAttr,omitempty"`
+	// +optional
+	GroupsAttr string `json:"groupsAttr,omitempty"`
+
+	// Issuer value for the AuthnRequest. Some IdPs require it.
+	// +optional
+	EntityIssuer string `json:"entityIssuer,omitempty"`
+
+	// Issuer value expected in the SAML response.
+	// +optional
+	SSOIssuer string `json:"ssoIssuer,omitempty"`
+
+	// Requested format of the NameID (ie, "persistent" or "transient").
+	// +optional
+	NameIDPolicyFormat string `json:"nameIDPolicyFormat,omitempty"`
+}
+
+// SAMLConnectorStatus defines the observed state of SAMLConnector
+type SAMLConnectorStatus struct {
+	ConnectorStatus `json:",inline"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+
+// SAMLConnector is the Schema for the samlconnectors API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ID",type="string",JSONPath=".status.connectorID"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type SAMLConnector struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SAMLConnectorSpec   `json:"spec,omitempty"`
+	Status SAMLConnectorStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+
+// SAMLConnectorList contains a list of SAMLConnector
+type SAMLConnectorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SAMLConnector `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SAMLConnector{}, &SAMLConnectorList{})
+}
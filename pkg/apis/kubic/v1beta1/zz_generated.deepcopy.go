@@ -22,9 +22,236 @@
 package v1beta1
 
 import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BitbucketCloudConnector) DeepCopyInto(out *BitbucketCloudConnector) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BitbucketCloudConnector.
+func (in *BitbucketCloudConnector) DeepCopy() *BitbucketCloudConnector {
+	if in == nil {
+		return nil
+	}
+	out := new(BitbucketCloudConnector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BitbucketCloudConnector) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BitbucketCloudConnectorList) DeepCopyInto(out *BitbucketCloudConnectorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BitbucketCloudConnector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BitbucketCloudConnectorList.
+func (in *BitbucketCloudConnectorList) DeepCopy() *BitbucketCloudConnectorList {
+	if in == nil {
+		return nil
+	}
+	out := new(BitbucketCloudConnectorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BitbucketCloudConnectorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BitbucketCloudConnectorSpec) DeepCopyInto(out *BitbucketCloudConnectorSpec) {
+	*out = *in
+	out.ClientSecretRef = in.ClientSecretRef
+	if in.Teams != nil {
+		in, out := &in.Teams, &out.Teams
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BitbucketCloudConnectorSpec.
+func (in *BitbucketCloudConnectorSpec) DeepCopy() *BitbucketCloudConnectorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BitbucketCloudConnectorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BitbucketCloudConnectorStatus) DeepCopyInto(out *BitbucketCloudConnectorStatus) {
+	*out = *in
+	in.ConnectorStatus.DeepCopyInto(&out.ConnectorStatus)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BitbucketCloudConnectorStatus.
+func (in *BitbucketCloudConnectorStatus) DeepCopy() *BitbucketCloudConnectorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BitbucketCloudConnectorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Condition) DeepCopyInto(out *Condition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Condition.
+func (in *Condition) DeepCopy() *Condition {
+	if in == nil {
+		return nil
+	}
+	out := new(Condition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectorInstanceStatus) DeepCopyInto(out *ConnectorInstanceStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectorInstanceStatus.
+func (in *ConnectorInstanceStatus) DeepCopy() *ConnectorInstanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectorInstanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectorStatus) DeepCopyInto(out *ConnectorStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]ConnectorInstanceStatus, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectorStatus.
+func (in *ConnectorStatus) DeepCopy() *ConnectorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DexCertManagerIssuerRef) DeepCopyInto(out *DexCertManagerIssuerRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DexCertManagerIssuerRef.
+func (in *DexCertManagerIssuerRef) DeepCopy() *DexCertManagerIssuerRef {
+	if in == nil {
+		return nil
+	}
+	out := new(DexCertManagerIssuerRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DexCertManagerSpec) DeepCopyInto(out *DexCertManagerSpec) {
+	*out = *in
+	out.IssuerRef = in.IssuerRef
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DexCertManagerSpec.
+func (in *DexCertManagerSpec) DeepCopy() *DexCertManagerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DexCertManagerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DexConfigRevision) DeepCopyInto(out *DexConfigRevision) {
+	*out = *in
+	if in.Connectors != nil {
+		in, out := &in.Connectors, &out.Connectors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DexConfigRevision.
+func (in *DexConfigRevision) DeepCopy() *DexConfigRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(DexConfigRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DexConfiguration) DeepCopyInto(out *DexConfiguration) {
 	*out = *in
@@ -94,6 +321,17 @@ func (in *DexConfigurationSpec) DeepCopyInto(out *DexConfigurationSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ConnectorSelector != nil {
+		in, out := &in.ConnectorSelector, &out.ConnectorSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Expose.DeepCopyInto(&out.Expose)
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
 	if in.StaticClients != nil {
 		in, out := &in.StaticClients, &out.StaticClients
 		*out = make([]DexStaticClient, len(*in))
@@ -102,6 +340,7 @@ func (in *DexConfigurationSpec) DeepCopyInto(out *DexConfigurationSpec) {
 		}
 	}
 	out.Certificate = in.Certificate
+	in.CertManager.DeepCopyInto(&out.CertManager)
 	return
 }
 
@@ -118,7 +357,41 @@ func (in *DexConfigurationSpec) DeepCopy() *DexConfigurationSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DexConfigurationStatus) DeepCopyInto(out *DexConfigurationStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Rollout.DeepCopyInto(&out.Rollout)
+	if in.Revisions != nil {
+		in, out := &in.Revisions, &out.Revisions
+		*out = make([]DexConfigRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FailedConfigHashes != nil {
+		in, out := &in.FailedConfigHashes, &out.FailedConfigHashes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Connectors != nil {
+		in, out := &in.Connectors, &out.Connectors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RejectedConnectors != nil {
+		in, out := &in.RejectedConnectors, &out.RejectedConnectors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	out.GeneratedCertificate = in.GeneratedCertificate
+	if in.CertificateNotAfter != nil {
+		in, out := &in.CertificateNotAfter, &out.CertificateNotAfter
+		*out = (*in).DeepCopy()
+	}
 	if in.StaticClients != nil {
 		in, out := &in.StaticClients, &out.StaticClients
 		*out = make([]DexStaticClientStatus, len(*in))
@@ -139,6 +412,78 @@ func (in *DexConfigurationStatus) DeepCopy() *DexConfigurationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DexExposeSpec) DeepCopyInto(out *DexExposeSpec) {
+	*out = *in
+	if in.ServiceAnnotations != nil {
+		in, out := &in.ServiceAnnotations, &out.ServiceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Ingress.DeepCopyInto(&out.Ingress)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DexExposeSpec.
+func (in *DexExposeSpec) DeepCopy() *DexExposeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DexExposeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DexIngressSpec) DeepCopyInto(out *DexIngressSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DexIngressSpec.
+func (in *DexIngressSpec) DeepCopy() *DexIngressSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DexIngressSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DexRolloutStatus) DeepCopyInto(out *DexRolloutStatus) {
+	*out = *in
+	if in.Connectors != nil {
+		in, out := &in.Connectors, &out.Connectors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DexRolloutStatus.
+func (in *DexRolloutStatus) DeepCopy() *DexRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DexRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DexStaticClient) DeepCopyInto(out *DexStaticClient) {
 	*out = *in
@@ -183,27 +528,27 @@ func (in *DexStaticClientStatus) DeepCopy() *DexStaticClientStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LDAPConnector) DeepCopyInto(out *LDAPConnector) {
+func (in *GitHubConnector) DeepCopyInto(out *GitHubConnector) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAPConnector.
-func (in *LDAPConnector) DeepCopy() *LDAPConnector {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitHubConnector.
+func (in *GitHubConnector) DeepCopy() *GitHubConnector {
 	if in == nil {
 		return nil
 	}
-	out := new(LDAPConnector)
+	out := new(GitHubConnector)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LDAPConnector) DeepCopyObject() runtime.Object {
+func (in *GitHubConnector) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -211,13 +556,13 @@ func (in *LDAPConnector) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LDAPConnectorList) DeepCopyInto(out *LDAPConnectorList) {
+func (in *GitHubConnectorList) DeepCopyInto(out *GitHubConnectorList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	out.ListMeta = in.ListMeta
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]LDAPConnector, len(*in))
+		*out = make([]GitHubConnector, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -225,18 +570,18 @@ func (in *LDAPConnectorList) DeepCopyInto(out *LDAPConnectorList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAPConnectorList.
-func (in *LDAPConnectorList) DeepCopy() *LDAPConnectorList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitHubConnectorList.
+func (in *GitHubConnectorList) DeepCopy() *GitHubConnectorList {
 	if in == nil {
 		return nil
 	}
-	out := new(LDAPConnectorList)
+	out := new(GitHubConnectorList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LDAPConnectorList) DeepCopyObject() runtime.Object {
+func (in *GitHubConnectorList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -244,41 +589,368 @@ func (in *LDAPConnectorList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LDAPConnectorSpec) DeepCopyInto(out *LDAPConnectorSpec) {
+func (in *GitHubConnectorSpec) DeepCopyInto(out *GitHubConnectorSpec) {
 	*out = *in
-	out.User = in.User
-	out.Group = in.Group
+	out.ClientSecretRef = in.ClientSecretRef
+	if in.Orgs != nil {
+		in, out := &in.Orgs, &out.Orgs
+		*out = make([]GitHubOrgSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAPConnectorSpec.
-func (in *LDAPConnectorSpec) DeepCopy() *LDAPConnectorSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitHubConnectorSpec.
+func (in *GitHubConnectorSpec) DeepCopy() *GitHubConnectorSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(LDAPConnectorSpec)
+	out := new(GitHubConnectorSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LDAPConnectorStatus) DeepCopyInto(out *LDAPConnectorStatus) {
+func (in *GitHubConnectorStatus) DeepCopyInto(out *GitHubConnectorStatus) {
 	*out = *in
+	in.ConnectorStatus.DeepCopyInto(&out.ConnectorStatus)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAPConnectorStatus.
-func (in *LDAPConnectorStatus) DeepCopy() *LDAPConnectorStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitHubConnectorStatus.
+func (in *GitHubConnectorStatus) DeepCopy() *GitHubConnectorStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(LDAPConnectorStatus)
+	out := new(GitHubConnectorStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LDAPGroupSpec) DeepCopyInto(out *LDAPGroupSpec) {
+func (in *GitHubOrgSpec) DeepCopyInto(out *GitHubOrgSpec) {
+	*out = *in
+	if in.Teams != nil {
+		in, out := &in.Teams, &out.Teams
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitHubOrgSpec.
+func (in *GitHubOrgSpec) DeepCopy() *GitHubOrgSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GitHubOrgSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitLabConnector) DeepCopyInto(out *GitLabConnector) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitLabConnector.
+func (in *GitLabConnector) DeepCopy() *GitLabConnector {
+	if in == nil {
+		return nil
+	}
+	out := new(GitLabConnector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GitLabConnector) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitLabConnectorList) DeepCopyInto(out *GitLabConnectorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GitLabConnector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitLabConnectorList.
+func (in *GitLabConnectorList) DeepCopy() *GitLabConnectorList {
+	if in == nil {
+		return nil
+	}
+	out := new(GitLabConnectorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GitLabConnectorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitLabConnectorSpec) DeepCopyInto(out *GitLabConnectorSpec) {
+	*out = *in
+	out.ClientSecretRef = in.ClientSecretRef
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitLabConnectorSpec.
+func (in *GitLabConnectorSpec) DeepCopy() *GitLabConnectorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GitLabConnectorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitLabConnectorStatus) DeepCopyInto(out *GitLabConnectorStatus) {
+	*out = *in
+	in.ConnectorStatus.DeepCopyInto(&out.ConnectorStatus)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitLabConnectorStatus.
+func (in *GitLabConnectorStatus) DeepCopy() *GitLabConnectorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GitLabConnectorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoogleConnector) DeepCopyInto(out *GoogleConnector) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoogleConnector.
+func (in *GoogleConnector) DeepCopy() *GoogleConnector {
+	if in == nil {
+		return nil
+	}
+	out := new(GoogleConnector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GoogleConnector) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoogleConnectorList) DeepCopyInto(out *GoogleConnectorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GoogleConnector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoogleConnectorList.
+func (in *GoogleConnectorList) DeepCopy() *GoogleConnectorList {
+	if in == nil {
+		return nil
+	}
+	out := new(GoogleConnectorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GoogleConnectorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoogleConnectorSpec) DeepCopyInto(out *GoogleConnectorSpec) {
+	*out = *in
+	out.ClientSecretRef = in.ClientSecretRef
+	if in.HostedDomains != nil {
+		in, out := &in.HostedDomains, &out.HostedDomains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.ServiceAccountRef = in.ServiceAccountRef
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoogleConnectorSpec.
+func (in *GoogleConnectorSpec) DeepCopy() *GoogleConnectorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GoogleConnectorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoogleConnectorStatus) DeepCopyInto(out *GoogleConnectorStatus) {
+	*out = *in
+	in.ConnectorStatus.DeepCopyInto(&out.ConnectorStatus)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoogleConnectorStatus.
+func (in *GoogleConnectorStatus) DeepCopy() *GoogleConnectorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GoogleConnectorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LDAPConnector) DeepCopyInto(out *LDAPConnector) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAPConnector.
+func (in *LDAPConnector) DeepCopy() *LDAPConnector {
+	if in == nil {
+		return nil
+	}
+	out := new(LDAPConnector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LDAPConnector) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LDAPConnectorList) DeepCopyInto(out *LDAPConnectorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LDAPConnector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAPConnectorList.
+func (in *LDAPConnectorList) DeepCopy() *LDAPConnectorList {
+	if in == nil {
+		return nil
+	}
+	out := new(LDAPConnectorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LDAPConnectorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LDAPConnectorSpec) DeepCopyInto(out *LDAPConnectorSpec) {
+	*out = *in
+	out.BindPWSecretRef = in.BindPWSecretRef
+	out.RootCARef = in.RootCARef
+	out.User = in.User
+	out.Group = in.Group
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAPConnectorSpec.
+func (in *LDAPConnectorSpec) DeepCopy() *LDAPConnectorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LDAPConnectorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LDAPConnectorStatus) DeepCopyInto(out *LDAPConnectorStatus) {
+	*out = *in
+	in.ConnectorStatus.DeepCopyInto(&out.ConnectorStatus)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAPConnectorStatus.
+func (in *LDAPConnectorStatus) DeepCopy() *LDAPConnectorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LDAPConnectorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LDAPGroupSpec) DeepCopyInto(out *LDAPGroupSpec) {
 	*out = *in
 	return
 }
@@ -288,23 +960,367 @@ func (in *LDAPGroupSpec) DeepCopy() *LDAPGroupSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(LDAPGroupSpec)
+	out := new(LDAPGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LDAPUserSpec) DeepCopyInto(out *LDAPUserSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAPUserSpec.
+func (in *LDAPUserSpec) DeepCopy() *LDAPUserSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LDAPUserSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MicrosoftConnector) DeepCopyInto(out *MicrosoftConnector) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MicrosoftConnector.
+func (in *MicrosoftConnector) DeepCopy() *MicrosoftConnector {
+	if in == nil {
+		return nil
+	}
+	out := new(MicrosoftConnector)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MicrosoftConnector) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LDAPUserSpec) DeepCopyInto(out *LDAPUserSpec) {
+func (in *MicrosoftConnectorList) DeepCopyInto(out *MicrosoftConnectorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MicrosoftConnector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MicrosoftConnectorList.
+func (in *MicrosoftConnectorList) DeepCopy() *MicrosoftConnectorList {
+	if in == nil {
+		return nil
+	}
+	out := new(MicrosoftConnectorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MicrosoftConnectorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MicrosoftConnectorSpec) DeepCopyInto(out *MicrosoftConnectorSpec) {
 	*out = *in
+	out.ClientSecretRef = in.ClientSecretRef
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAPUserSpec.
-func (in *LDAPUserSpec) DeepCopy() *LDAPUserSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MicrosoftConnectorSpec.
+func (in *MicrosoftConnectorSpec) DeepCopy() *MicrosoftConnectorSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(LDAPUserSpec)
+	out := new(MicrosoftConnectorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MicrosoftConnectorStatus) DeepCopyInto(out *MicrosoftConnectorStatus) {
+	*out = *in
+	in.ConnectorStatus.DeepCopyInto(&out.ConnectorStatus)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MicrosoftConnectorStatus.
+func (in *MicrosoftConnectorStatus) DeepCopy() *MicrosoftConnectorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MicrosoftConnectorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCClaimMappingSpec) DeepCopyInto(out *OIDCClaimMappingSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCClaimMappingSpec.
+func (in *OIDCClaimMappingSpec) DeepCopy() *OIDCClaimMappingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCClaimMappingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCConnector) DeepCopyInto(out *OIDCConnector) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCConnector.
+func (in *OIDCConnector) DeepCopy() *OIDCConnector {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCConnector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OIDCConnector) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCConnectorList) DeepCopyInto(out *OIDCConnectorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OIDCConnector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCConnectorList.
+func (in *OIDCConnectorList) DeepCopy() *OIDCConnectorList {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCConnectorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OIDCConnectorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCConnectorSpec) DeepCopyInto(out *OIDCConnectorSpec) {
+	*out = *in
+	out.ClientSecretRef = in.ClientSecretRef
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.ClaimMapping = in.ClaimMapping
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCConnectorSpec.
+func (in *OIDCConnectorSpec) DeepCopy() *OIDCConnectorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCConnectorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCConnectorStatus) DeepCopyInto(out *OIDCConnectorStatus) {
+	*out = *in
+	in.ConnectorStatus.DeepCopyInto(&out.ConnectorStatus)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCConnectorStatus.
+func (in *OIDCConnectorStatus) DeepCopy() *OIDCConnectorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCConnectorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectKeyReference) DeepCopyInto(out *ObjectKeyReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectKeyReference.
+func (in *ObjectKeyReference) DeepCopy() *ObjectKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAMLConnector) DeepCopyInto(out *SAMLConnector) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAMLConnector.
+func (in *SAMLConnector) DeepCopy() *SAMLConnector {
+	if in == nil {
+		return nil
+	}
+	out := new(SAMLConnector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SAMLConnector) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAMLConnectorList) DeepCopyInto(out *SAMLConnectorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SAMLConnector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAMLConnectorList.
+func (in *SAMLConnectorList) DeepCopy() *SAMLConnectorList {
+	if in == nil {
+		return nil
+	}
+	out := new(SAMLConnectorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SAMLConnectorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAMLConnectorSpec) DeepCopyInto(out *SAMLConnectorSpec) {
+	*out = *in
+	out.CARef = in.CARef
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAMLConnectorSpec.
+func (in *SAMLConnectorSpec) DeepCopy() *SAMLConnectorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SAMLConnectorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAMLConnectorStatus) DeepCopyInto(out *SAMLConnectorStatus) {
+	*out = *in
+	in.ConnectorStatus.DeepCopyInto(&out.ConnectorStatus)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAMLConnectorStatus.
+func (in *SAMLConnectorStatus) DeepCopy() *SAMLConnectorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SAMLConnectorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeyReference) DeepCopyInto(out *SecretKeyReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyReference.
+func (in *SecretKeyReference) DeepCopy() *SecretKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyReference)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -26,6 +26,7 @@ import (
 	"github.com/golang/glog"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	extv1beta1 "k8s.io/api/extensions/v1beta1"
 	netv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -106,9 +107,19 @@ func CreateOrUpdateService(client clientset.Interface, service *corev1.Service)
 	} else if err != nil {
 		return nil, err
 	} else {
-		if !reflect.DeepEqual(service.Spec, existing.Spec) {
+		if !reflect.DeepEqual(service.Spec, existing.Spec) || !reflect.DeepEqual(service.Annotations, existing.Annotations) {
+			// keep the NodePorts allocated when not specified (and still used)
+			ports := []corev1.ServicePort{}
+			for i, port := range service.Spec.Ports {
+				if port.NodePort == 0 && service.Spec.Type != corev1.ServiceTypeClusterIP && i < len(existing.Spec.Ports) {
+					port.NodePort = existing.Spec.Ports[i].NodePort
+				}
+				ports = append(ports, port)
+			}
 			existing.Spec.Type = service.Spec.Type
-			existing.Spec.Ports = service.Spec.Ports
+			existing.Spec.Ports = ports
+			existing.Spec.LoadBalancerIP = service.Spec.LoadBalancerIP
+			existing.Annotations = service.Annotations
 			if existing, err = client.Core().Services(existing.GetNamespace()).Update(existing); err != nil {
 				return nil, fmt.Errorf("unable to update Service: %v", err)
 			}
@@ -133,6 +144,44 @@ func DeleteServiceForeground(client clientset.Interface, service *corev1.Service
 	return nil
 }
 
+// CreateOrUpdateIngress creates an Ingress if the target resource doesn't exist. If the resource exists
+// already, this function will update the resource instead.
+func CreateOrUpdateIngress(client clientset.Interface, ingress *extv1beta1.Ingress) (*extv1beta1.Ingress, error) {
+	var err error
+	var existing *extv1beta1.Ingress
+
+	existing, err = client.Extensions().Ingresses(ingress.GetNamespace()).Get(ingress.GetName(), metav1.GetOptions{})
+	if err != nil && apierrors.IsNotFound(err) {
+		if existing, err = client.Extensions().Ingresses(ingress.GetNamespace()).Create(ingress); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	} else {
+		if !reflect.DeepEqual(ingress.Spec, existing.Spec) || !reflect.DeepEqual(ingress.Annotations, existing.Annotations) {
+			existing.Spec = ingress.Spec
+			existing.Annotations = ingress.Annotations
+			if existing, err = client.Extensions().Ingresses(existing.GetNamespace()).Update(existing); err != nil {
+				return nil, fmt.Errorf("unable to update Ingress: %v", err)
+			}
+		}
+	}
+
+	return existing, nil
+}
+
+// DeleteIngressForeground deletes an Ingress
+// Deletion is performed in foreground mode; i.e. it blocks until/makes sure
+// all the resources are deleted.
+func DeleteIngressForeground(client clientset.Interface, ingress *extv1beta1.Ingress) error {
+	foregroundDelete := metav1.DeletePropagationForeground
+	deleteOptions := &metav1.DeleteOptions{
+		PropagationPolicy: &foregroundDelete,
+	}
+
+	return client.Extensions().Ingresses(ingress.GetNamespace()).Delete(ingress.GetName(), deleteOptions)
+}
+
 // CreateOrUpdateNetworkPolicy returns the NetworkPolicy
 func CreateOrUpdateNetworkPolicy(client clientset.Interface, np *netv1.NetworkPolicy) (*netv1.NetworkPolicy, error) {
 	var unp *netv1.NetworkPolicy
@@ -23,15 +23,40 @@ const (
 )
 
 const (
+	// DefaultConfigurationName the name of the main DexConfiguration (the objects
+	// created for this instance do not include the instance name)
+	DefaultConfigurationName = "dex-configuration"
+
 	// DefaultConfigMapFilename Configmap (in the conatiner)
 	DefaultConfigMapFilename = "/etc/dex/cfg/config.yaml"
 
+	// DefaultImage the image used for Dex
+	DefaultImage = "registry.opensuse.org/devel/caasp/kubic-container/container/kubic/caasp-dex:2.7.1"
+
+	// DefaultAdminGroup the group (in the connectors) with cluster administrators
+	DefaultAdminGroup = "Administrators"
+
 	// DefaultNodePort Default Dex port
 	DefaultNodePort = 32000
 
+	// DefaultProgressDeadlineSeconds the time for a rollout of Dex to make progress
+	DefaultProgressDeadlineSeconds = 300
+
+	// DefaultRevisionHistoryLimit the number of good Dex configurations kept for rollbacks
+	DefaultRevisionHistoryLimit = 3
+
+	// DefaultCertificateRenewBeforeDays the days before the expiration when the generated certificate is renewed
+	DefaultCertificateRenewBeforeDays = 30
+
+	// DefaultCertificateKeyAlgorithm the algorithm for the private key of the generated certificate
+	DefaultCertificateKeyAlgorithm = "RSA-2048"
+
 	// DefaultCertsDir the directory where certs are stored (in the container)
 	DefaultCertsDir = "/etc/dex/tls"
 
+	// DefaultCredentialsDir the directory where connectors credentials are stored (in the container)
+	DefaultCredentialsDir = "/etc/dex/credentials"
+
 	// DefaultSharedPasswordLen the length (in bytes) for random passwords
 	DefaultSharedPasswordLen = 16
 )
@@ -21,6 +21,15 @@ var (
 	// DefaultPrefix A prefix for all the resources created by the operator
 	DefaultPrefix = "dexop"
 
+	// DefaultNamespace The namespace where Dex is run (when not specified in the DexConfiguration)
+	DefaultNamespace = "kube-system"
+
 	// DefaultDeployNumReplicas Default number of replicas for the Deployment
 	DefaultDeployNumReplicas = 3
+
+	// WebhooksEnabled Run the admission webhooks server
+	WebhooksEnabled = true
+
+	// DefaultWebhookPort Port for the admission webhooks server
+	DefaultWebhookPort = 9876
 )
@@ -18,9 +18,13 @@
 package dex
 
 import (
+	"context"
 	"crypto/sha256"
 	"fmt"
 	"net"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/golang/glog"
 	"github.com/kubic-project/dex-operator/pkg/crypto"
@@ -28,12 +32,23 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	certutil "k8s.io/client-go/util/cert"
 
 	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
-	dexcfg "github.com/kubic-project/dex-operator/pkg/config"
 	dexnet "github.com/kubic-project/dex-operator/pkg/net"
 )
 
+const (
+	// minimum time between checks of the certificate expiration
+	dexCertificateMinRequeuePeriod = 5 * time.Minute
+
+	// maximum time waiting for a CSR to be signed in a reconciliation
+	dexCSRWaitTimeout = 30 * time.Second
+
+	// time before checking again a CSR that has not been signed
+	dexCSRRequeuePeriod = 30 * time.Second
+)
+
 // Certificate struct
 type Certificate struct {
 	instance *kubicv1beta1.DexConfiguration
@@ -41,6 +56,10 @@ type Certificate struct {
 	existing   *corev1.Secret
 	generated  *corev1.Secret
 	reconciler *ReconcileDexConfiguration
+
+	// a warning about the certificate provided by the user (ie, "Expiring")
+	warningReason  string
+	warningMessage string
 }
 
 // NewCertificate returns a new *dex.Certificate struct
@@ -50,6 +69,8 @@ func NewCertificate(instance *kubicv1beta1.DexConfiguration, reconciler *Reconci
 		nil,
 		nil,
 		reconciler,
+		"",
+		"",
 	}
 
 	if err := cert.GetFrom(instance); err != nil {
@@ -65,6 +86,11 @@ func (cert *Certificate) GetFrom(instance *kubicv1beta1.DexConfiguration) error
 
 	if len(instance.Spec.Certificate.Name) > 0 {
 		name, namespace = instance.Spec.Certificate.Name, instance.Spec.Certificate.Namespace
+		if len(namespace) == 0 {
+			namespace = getNamespace(instance)
+		}
+	} else if usesCertManager(instance) {
+		name, namespace = getCertManagerName(instance), getNamespace(instance)
 	} else if len(instance.Status.GeneratedCertificate.Name) > 0 {
 		name, namespace = instance.Status.GeneratedCertificate.Name, instance.Status.GeneratedCertificate.Namespace
 	} else {
@@ -93,6 +119,21 @@ func (cert Certificate) WasGenerated() bool {
 	return cert.generated != nil
 }
 
+// IsProvided checks if the cert has been provided by the user (in the spec)
+func (cert Certificate) IsProvided() bool {
+	return len(cert.instance.Spec.Certificate.Name) > 0
+}
+
+// GetExpiration returns the expiration time of the certificate
+func (cert Certificate) GetExpiration() (time.Time, error) {
+	if cert.existing != nil {
+		return crypto.GetExpiration(cert.existing)
+	} else if cert.generated != nil {
+		return crypto.GetExpiration(cert.generated)
+	}
+	return time.Time{}, fmt.Errorf("no certificate available for '%s'", cert.instance.GetName())
+}
+
 // GetHashRequested get the requested hash
 func (cert Certificate) GetHashRequested() string {
 	var data []byte
@@ -110,44 +151,202 @@ func (cert Certificate) GetHashRequested() string {
 // CreateOrUpdate creates the Service in the apiserver, or updates an existing instance
 func (cert *Certificate) CreateOrUpdate(deployment *Deployment) error {
 
-	// TODO: check if cert.existing is valid
+	if usesCertManager(cert.instance) {
+		return cert.createOrUpdateCertManager()
+	}
+	// remove the cert-manager Certificate when it is not used anymore
+	if err := cert.reconciler.deleteCertManagerCertificate(cert.instance); err != nil {
+		return err
+	}
+
+	certIPs, certNames := cert.getRequestedSANs(deployment)
+	renewBefore := getCertificateRenewBefore(cert.instance)
 	if cert.existing != nil {
-		glog.V(3).Infof("[kubic] Dex's service certificate is already in the apiserver: no need to update/create")
-		return nil
+		if cert.IsProvided() {
+			// we cannot replace certificates provided by the user
+			cert.checkProvided(renewBefore)
+			return nil
+		}
+
+		replace, err := cert.mustBeReplaced(certIPs, certNames, renewBefore)
+		if err != nil {
+			return err
+		}
+		if !replace {
+			glog.V(3).Infof("[kubic] Dex's service certificate is already in the apiserver: no need to update/create")
+			return nil
+		}
 	}
 
-	defaultAddress, err := dexnet.GetBindIP()
+	certificate, err := crypto.NewAutoCert(certIPs, certNames, cert.GetName(), cert.GetNamespace())
+	if err != nil {
+		return err
+	}
+	certificate.RenewBefore = renewBefore
+	certificate.KeyAlgorithm = cert.instance.Spec.CertificateKeyAlgorithm
+	certificate.SignerName = cert.instance.Spec.CertificateSignerName
+	cert.reconciler.EventRecorder.Event(cert.instance, corev1.EventTypeNormal,
+		"Checking", fmt.Sprintf("Getting certificate '%s' for '%s'...", certificate.GetName(), cert.instance.GetName()))
+
+	// do not block the reconciliation when there is no signer
+	ctx, cancel := context.WithTimeout(context.Background(), dexCSRWaitTimeout)
+	defer cancel()
+	csrs := crypto.NewCSRClient(cert.reconciler.Clientset, cert.reconciler.Dynamic)
+	if cert.existing != nil {
+		// the existing certificate must be replaced
+		cert.generated, err = certificate.Request(ctx, cert.reconciler.Clientset, csrs)
+	} else {
+		cert.generated, err = certificate.GetOrRequest(ctx, cert.reconciler.Clientset, csrs)
+	}
 	if err != nil {
+		glog.V(3).Infof("[kubic] could not create/update certificate '%s': %s", util.NamespacedObjToString(cert), err)
+		cert.generated = nil
 		return err
 	}
+	cert.existing = nil
+
+	return nil
+}
+
+// checkProvided checks if the certificate provided by the user is about to expire or
+// uses a weak key. The Warning event is only emitted when the reason of the
+// CertificateReady condition changes, so it is not repeated in every reconciliation.
+func (cert *Certificate) checkProvided(renewBefore time.Duration) {
+	cert.warningReason, cert.warningMessage = "", ""
+	if crypto.NeedsRenewal(cert.existing, renewBefore) {
+		cert.warningReason = "Expiring"
+		cert.warningMessage = fmt.Sprintf("Certificate '%s' expires in less than %s: it must be renewed", cert, renewBefore)
+	} else if algorithm, err := crypto.GetKeyAlgorithm(cert.existing); err == nil && crypto.IsWeakKeyAlgorithm(algorithm) {
+		cert.warningReason = "WeakKey"
+		cert.warningMessage = fmt.Sprintf("Certificate '%s' uses a weak %s key: it must be replaced", cert, algorithm)
+	}
+	if len(cert.warningReason) == 0 {
+		return
+	}
+
+	prev := getCondition(cert.instance.Status.Conditions, kubicv1beta1.DexCertificateReady)
+	if prev == nil || prev.Reason != cert.warningReason {
+		cert.reconciler.EventRecorder.Event(cert.instance, corev1.EventTypeWarning, cert.warningReason, cert.warningMessage)
+	}
+}
+
+// GetWarning returns the reason and the message of a warning about the certificate
+// provided by the user, or an empty reason if there is nothing to worry about
+func (cert Certificate) GetWarning() (string, string) {
+	return cert.warningReason, cert.warningMessage
+}
 
+// mustBeReplaced checks if the existing (generated) certificate must be replaced by a new one
+// because it is about to expire, it uses a different key algorithm or the SANs have changed
+func (cert Certificate) mustBeReplaced(certIPs []net.IP, certNames []string, renewBefore time.Duration) (bool, error) {
+	if crypto.NeedsRenewal(cert.existing, renewBefore) {
+		glog.V(3).Infof("[kubic] Dex's service certificate expires in less than %s: renewing", renewBefore)
+		cert.reconciler.EventRecorder.Event(cert.instance, corev1.EventTypeNormal,
+			"Renewing", fmt.Sprintf("Certificate '%s' expires in less than %s: renewing", cert, renewBefore))
+		return true, nil
+	}
+
+	algorithm, err := crypto.GetKeyAlgorithm(cert.existing)
+	if err != nil {
+		return false, err
+	}
+	requested := cert.instance.Spec.CertificateKeyAlgorithm
+	if len(requested) == 0 {
+		requested = crypto.DefaultKeyAlgorithm
+	}
+	if algorithm != requested {
+		glog.V(3).Infof("[kubic] Dex's service certificate uses a %s key (instead of %s): rotating", algorithm, requested)
+		cert.reconciler.EventRecorder.Event(cert.instance, corev1.EventTypeNormal,
+			"Rotating", fmt.Sprintf("Certificate '%s' uses a %s key (instead of %s): rotating", cert, algorithm, requested))
+		return true, nil
+	}
+
+	added, removed, err := crypto.DiffSANs(cert.existing, certIPs, certNames)
+	if err != nil {
+		return false, err
+	}
+	if len(added) > 0 || len(removed) > 0 {
+		glog.V(3).Infof("[kubic] Dex's service certificate SANs have changed (added: %v, removed: %v): regenerating", added, removed)
+		cert.reconciler.EventRecorder.Event(cert.instance, corev1.EventTypeNormal,
+			"Regenerating", fmt.Sprintf("Certificate '%s' SANs have changed (added: %s, removed: %s): regenerating",
+				cert, strings.Join(added, ","), strings.Join(removed, ",")))
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// getRequestedSANs returns the IPs and names that must be in the generated certificate.
+// The address of the operator is not included, as it changes whenever it is restarted.
+func (cert Certificate) getRequestedSANs(deployment *Deployment) ([]net.IP, []string) {
 	certIPs := []net.IP{
-		defaultAddress,
 		net.ParseIP("127.0.0.1"),
 	}
 	certNames := []string{
-		dexServiceName,
+		getDexServiceName(cert.instance),
 		dexnet.GetServiceDNSName(deployment),
 		deployment.GetName(),
 	}
 	certNames = append(certNames, cert.instance.Spec.Names...)
 
-	certificate, err := crypto.NewAutoCert(certIPs, certNames, cert.GetName(), cert.GetNamespace())
-	if err != nil {
-		return err
+	issuerIPs, issuerNames := getIssuerSANs(cert.instance)
+	return append(certIPs, issuerIPs...), util.RemoveDuplicates(append(certNames, issuerNames...))
+}
+
+// getIssuerSANs returns the host in the issuer URL (as an IP or a name), as it
+// must be valid for the certificate
+func getIssuerSANs(instance *kubicv1beta1.DexConfiguration) ([]net.IP, []string) {
+	u, err := url.Parse(instance.Status.Issuer)
+	if err != nil || len(u.Hostname()) == 0 {
+		return nil, nil
 	}
-	cert.reconciler.EventRecorder.Event(cert.instance, corev1.EventTypeNormal,
-		"Checking", fmt.Sprintf("Getting certificate '%s' for '%s'...", certificate.GetName(), cert.instance.GetName()))
-	cert.generated, err = certificate.GetOrRequest(cert.reconciler.Clientset)
+	if ip := net.ParseIP(u.Hostname()); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	return nil, []string{u.Hostname()}
+}
+
+// VerifyIssuer checks that the certificate is valid for the host in the `issuer` URL
+func (cert Certificate) VerifyIssuer(issuer string) error {
+	u, err := url.Parse(issuer)
 	if err != nil {
-		glog.V(3).Infof("[kubic] could not create/update certificate '%s': %s", util.NamespacedObjToString(cert), err)
-		cert.generated = nil
 		return err
 	}
 
+	var data []byte
+	if cert.existing != nil {
+		data = cert.existing.Data[corev1.TLSCertKey]
+	} else if cert.generated != nil {
+		data = cert.generated.Data[corev1.TLSCertKey]
+	}
+	certs, err := certutil.ParseCertsPEM(data)
+	if err != nil {
+		return fmt.Errorf("could not parse certificate '%s': %s", cert, err)
+	}
+	if err := certs[0].VerifyHostname(u.Hostname()); err != nil {
+		return fmt.Errorf("certificate '%s' cannot be used for the issuer %s: %s", cert, issuer, err)
+	}
 	return nil
 }
 
+// getCertificateRenewBefore returns the time before the expiration when the certificate must be renewed
+func getCertificateRenewBefore(instance *kubicv1beta1.DexConfiguration) time.Duration {
+	return time.Duration(instance.Spec.CertificateRenewBeforeDays) * 24 * time.Hour
+}
+
+// getCertificateRequeueAfter returns the time until the certificate must be checked again
+// (for renewing it), or 0 if the expiration is not known
+func getCertificateRequeueAfter(instance *kubicv1beta1.DexConfiguration) time.Duration {
+	if instance.Status.CertificateNotAfter == nil {
+		return 0
+	}
+	renewAt := instance.Status.CertificateNotAfter.Add(-getCertificateRenewBefore(instance))
+	if d := time.Until(renewAt); d > dexCertificateMinRequeuePeriod {
+		return d
+	}
+	return dexCertificateMinRequeuePeriod
+}
+
 // Delete delete the cert
 func (cert *Certificate) Delete() error {
 	if cert.generated != nil {
@@ -168,20 +367,29 @@ func (cert *Certificate) GetObject() metav1.Object {
 }
 
 // GetName returns the cert name
-// takes the form of [DefaultPrefix]-auto-cert
+// takes the form of [prefix]-auto-cert
 func (cert Certificate) GetName() string {
+	if cert.generated != nil {
+		return cert.generated.GetName()
+	}
 	if cert.existing != nil {
 		return cert.existing.GetName()
 	}
-	return fmt.Sprintf("%s-auto-cert", dexcfg.DefaultPrefix)
+	if usesCertManager(cert.instance) {
+		return getCertManagerName(cert.instance)
+	}
+	return fmt.Sprintf("%s-auto-cert", getPrefix(cert.instance))
 }
 
 // GetNamespace returns the namespace as a string
 func (cert Certificate) GetNamespace() string {
+	if cert.generated != nil {
+		return cert.generated.GetNamespace()
+	}
 	if cert.existing != nil {
 		return cert.existing.GetNamespace()
 	}
-	return metav1.NamespaceSystem
+	return getNamespace(cert.instance)
 }
 
 // String returns the namespacedObj of the cert as a string
@@ -192,7 +400,7 @@ func (cert Certificate) String() string {
 // AsSecretReference returns a SecretReference
 func (cert Certificate) AsSecretReference() corev1.SecretReference {
 	return corev1.SecretReference{
-		cert.GetName(),
-		cert.GetNamespace(),
+		Name:      cert.GetName(),
+		Namespace: cert.GetNamespace(),
 	}
 }
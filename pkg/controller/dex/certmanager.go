@@ -0,0 +1,267 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
+	"github.com/kubic-project/dex-operator/pkg/crypto"
+	"github.com/kubic-project/dex-operator/pkg/util"
+)
+
+const (
+	// time before checking again a cert-manager Certificate that is not ready
+	dexCertManagerRequeuePeriod = 10 * time.Second
+)
+
+var (
+	certManagerGroupVersion = schema.GroupVersion{Group: "cert-manager.io", Version: "v1"}
+
+	certManagerCertificatesResource = certManagerGroupVersion.WithResource("certificates")
+)
+
+// errCertManagerPending is returned while the Secret of the cert-manager Certificate is not available
+var errCertManagerPending = errors.New("cert-manager certificate not issued yet")
+
+// getCertificateMode returns the way the certificate for Dex is obtained
+func getCertificateMode(instance *kubicv1beta1.DexConfiguration) kubicv1beta1.DexCertificateMode {
+	if len(instance.Spec.CertificateMode) == 0 {
+		return kubicv1beta1.DexCertificateCSR
+	}
+	return instance.Spec.CertificateMode
+}
+
+// usesCertManager returns true when the certificate for Dex is requested to cert-manager
+func usesCertManager(instance *kubicv1beta1.DexConfiguration) bool {
+	return getCertificateMode(instance) == kubicv1beta1.DexCertificateCertManager &&
+		len(instance.Spec.Certificate.Name) == 0
+}
+
+// getCertManagerName returns the name of the cert-manager Certificate (and of its Secret)
+func getCertManagerName(instance *kubicv1beta1.DexConfiguration) string {
+	return fmt.Sprintf("%s-cert", getPrefix(instance))
+}
+
+// getCertManagerSANs returns the IPs and names requested to cert-manager: only the `names`
+// and the host in the issuer, as public issuers (ie, ACME) reject internal names and IPs
+func getCertManagerSANs(instance *kubicv1beta1.DexConfiguration) ([]net.IP, []string) {
+	certIPs, certNames := getIssuerSANs(instance)
+	return certIPs, util.RemoveDuplicates(append(append([]string{}, instance.Spec.Names...), certNames...))
+}
+
+// newCertManagerCertificate returns the cert-manager Certificate for Dex
+func newCertManagerCertificate(instance *kubicv1beta1.DexConfiguration, certIPs []net.IP, certNames []string) *unstructured.Unstructured {
+	issuerRef := map[string]interface{}{
+		"name": instance.Spec.CertManager.IssuerRef.Name,
+	}
+	if len(instance.Spec.CertManager.IssuerRef.Kind) > 0 {
+		issuerRef["kind"] = instance.Spec.CertManager.IssuerRef.Kind
+	}
+	if len(instance.Spec.CertManager.IssuerRef.Group) > 0 {
+		issuerRef["group"] = instance.Spec.CertManager.IssuerRef.Group
+	}
+
+	spec := map[string]interface{}{
+		"secretName": getCertManagerName(instance),
+		"issuerRef":  issuerRef,
+		"usages":     []interface{}{"digital signature", "key encipherment", "server auth"},
+		"privateKey": getCertManagerPrivateKey(instance.Spec.CertificateKeyAlgorithm),
+	}
+	if len(certNames) > 0 {
+		dnsNames := []interface{}{}
+		for _, name := range certNames {
+			dnsNames = append(dnsNames, name)
+		}
+		spec["commonName"] = certNames[0]
+		spec["dnsNames"] = dnsNames
+	}
+	if len(certIPs) > 0 {
+		ipAddresses := []interface{}{}
+		for _, ip := range certIPs {
+			ipAddresses = append(ipAddresses, ip.String())
+		}
+		spec["ipAddresses"] = ipAddresses
+	}
+	if instance.Spec.CertManager.Duration != nil {
+		spec["duration"] = instance.Spec.CertManager.Duration.Duration.String()
+	}
+	if instance.Spec.CertManager.RenewBefore != nil {
+		spec["renewBefore"] = instance.Spec.CertManager.RenewBefore.Duration.String()
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": certManagerGroupVersion.String(),
+			"kind":       "Certificate",
+			"metadata": map[string]interface{}{
+				"name":      getCertManagerName(instance),
+				"namespace": getNamespace(instance),
+			},
+			"spec": spec,
+		},
+	}
+}
+
+// getCertManagerPrivateKey returns the `privateKey` of a cert-manager Certificate
+// for one of the crypto.SupportedKeyAlgorithms (with a new key for every certificate)
+func getCertManagerPrivateKey(algorithm string) map[string]interface{} {
+	privateKey := map[string]interface{}{"rotationPolicy": "Always"}
+	switch algorithm {
+	case crypto.KeyRSA3072:
+		privateKey["algorithm"], privateKey["size"] = "RSA", int64(3072)
+	case crypto.KeyRSA4096:
+		privateKey["algorithm"], privateKey["size"] = "RSA", int64(4096)
+	case crypto.KeyECDSAP256:
+		privateKey["algorithm"], privateKey["size"] = "ECDSA", int64(256)
+	case crypto.KeyECDSAP384:
+		privateKey["algorithm"], privateKey["size"] = "ECDSA", int64(384)
+	case crypto.KeyEd25519:
+		privateKey["algorithm"] = "Ed25519"
+	default:
+		privateKey["algorithm"], privateKey["size"] = "RSA", int64(2048)
+	}
+	return privateKey
+}
+
+// createOrUpdateCertManager creates (or updates) the cert-manager Certificate for Dex, and
+// gets its Secret. It returns errCertManagerPending when the Secret is not available yet.
+func (cert *Certificate) createOrUpdateCertManager() error {
+	r := cert.reconciler
+
+	certIPs, certNames := getCertManagerSANs(cert.instance)
+	if len(certIPs) == 0 && len(certNames) == 0 {
+		return fmt.Errorf("no names for the cert-manager Certificate: set the names of Dex")
+	}
+
+	// the dynamic client cannot tell a missing API from a missing object
+	if _, err := r.Clientset.Discovery().ServerResourcesForGroupVersion(certManagerGroupVersion.String()); err != nil {
+		return fmt.Errorf("the %s API is not available (is cert-manager installed?): %s", certManagerGroupVersion, err)
+	}
+
+	generated := newCertManagerCertificate(cert.instance, certIPs, certNames)
+	resource := r.Dynamic.Resource(certManagerCertificatesResource).Namespace(generated.GetNamespace())
+
+	current, err := resource.Get(generated.GetName(), metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		glog.V(3).Infof("[kubic] creating cert-manager Certificate '%s'", util.NamespacedObjToString(generated))
+		if _, err = resource.Create(generated); err != nil {
+			return err
+		}
+		r.EventRecorder.Event(cert.instance, corev1.EventTypeNormal,
+			"Checking", fmt.Sprintf("cert-manager Certificate '%s' created for '%s'",
+				util.NamespacedObjToString(generated), cert.instance.GetName()))
+	case err != nil:
+		return err
+	case !isCertManagerSpecApplied(current, generated):
+		glog.V(3).Infof("[kubic] updating cert-manager Certificate '%s'", util.NamespacedObjToString(generated))
+		current.Object["spec"] = generated.Object["spec"]
+		if _, err = resource.Update(current); err != nil {
+			return err
+		}
+	}
+	cert.instance.Status.CertManagerCertificate = util.NamespacedObjToString(generated)
+
+	if cert.existing == nil {
+		cert.existing, err = r.Clientset.CoreV1().Secrets(generated.GetNamespace()).Get(generated.GetName(), metav1.GetOptions{})
+		if err != nil {
+			cert.existing = nil
+			if apierrors.IsNotFound(err) {
+				return errCertManagerPending
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// isCertManagerSpecApplied returns true if all the fields in the spec of the `generated`
+// Certificate have the same values in the `current` Certificate (that can contain some other
+// fields set by cert-manager)
+func isCertManagerSpecApplied(current, generated *unstructured.Unstructured) bool {
+	currentSpec, _, _ := unstructured.NestedMap(current.Object, "spec")
+	generatedSpec, _, _ := unstructured.NestedMap(generated.Object, "spec")
+	for key, value := range generatedSpec {
+		if !equality.Semantic.DeepEqual(currentSpec[key], value) {
+			return false
+		}
+	}
+	return true
+}
+
+// deleteCertManagerCertificate deletes the cert-manager Certificate (and its Secret)
+// created for a DexConfiguration
+func (r *ReconcileDexConfiguration) deleteCertManagerCertificate(instance *kubicv1beta1.DexConfiguration) error {
+	if len(instance.Status.CertManagerCertificate) == 0 {
+		return nil
+	}
+
+	nname := util.StringToNamespacedName(instance.Status.CertManagerCertificate)
+	glog.V(3).Infof("[kubic] removing cert-manager Certificate '%s'", instance.Status.CertManagerCertificate)
+	err := r.Dynamic.Resource(certManagerCertificatesResource).Namespace(nname.Namespace).Delete(nname.Name, &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	// cert-manager does not remove the Secret
+	err = r.Clientset.CoreV1().Secrets(nname.Namespace).Delete(nname.Name, &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	instance.Status.CertManagerCertificate = ""
+	return nil
+}
+
+// getCertManagerRequests returns the requests for the DexConfigurations using a
+// cert-manager Secret, so Dex is restarted when the certificate is renewed
+func getCertManagerRequests(cli client.Client, secret metav1.Object) []reconcile.Request {
+	requests := []reconcile.Request{}
+
+	instances := &kubicv1beta1.DexConfigurationList{}
+	if err := cli.List(context.TODO(), &client.ListOptions{}, instances); err != nil {
+		glog.V(3).Infof("[kubic] ERROR: could not list DexConfigurations: %s", err)
+		return requests
+	}
+	for i := range instances.Items {
+		instance := &instances.Items[i]
+		if instance.Status.CertManagerCertificate == util.NamespacedObjToString(secret) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      instance.GetName(),
+					Namespace: instance.GetNamespace(),
+				}})
+		}
+	}
+	return requests
+}
@@ -22,7 +22,6 @@ import (
 
 	"github.com/golang/glog"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clientset "k8s.io/client-go/kubernetes"
 
 	dexcfg "github.com/kubic-project/dex-operator/pkg/config"
@@ -30,10 +29,6 @@ import (
 	"github.com/kubic-project/dex-operator/pkg/util"
 )
 
-const (
-	sharedPasswordNamespace = metav1.NamespaceSystem
-)
-
 // StaticClientsPasswords is a groups of static, shared passwords that can be saved
 // to k8s Secrets.
 type StaticClientsPasswords struct {
@@ -47,9 +42,9 @@ type StaticClientsPasswords struct {
 // if they are not found, new random passwords are generated,
 // but not persisted in the apiserver
 func NewStaticClientsPasswords(prefix string, namespace string) (StaticClientsPasswords, error) {
-	// by default, passwords are stored in the "kube-system" namespace
+	// by default, passwords are stored in the namespace where Dex is run
 	if len(namespace) == 0 {
-		namespace = sharedPasswordNamespace
+		namespace = dexcfg.DefaultNamespace
 	}
 
 	sps := StaticClientsPasswords{
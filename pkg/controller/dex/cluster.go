@@ -21,6 +21,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/kubernetes/kubernetes/cmd/kubeadm/app/util/apiclient"
 	corev1 "k8s.io/api/core/v1"
+	extv1beta1 "k8s.io/api/extensions/v1beta1"
 	netv1 "k8s.io/api/networking/v1"
 	rbac "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -34,15 +35,18 @@ import (
 )
 
 const (
-	// The namespace where Dex will be run
-	dexDefaultNamespace = metav1.NamespaceSystem
-
 	// name of the Dex service
 	dexServiceName = "kubic-dex"
 
 	// dexServiceAccountName describes the name of the ServiceAccount for the dex addon
 	dexServiceAccountName = "kubic-dex"
 
+	// name of the Ingress (when Dex is exposed with an Ingress)
+	dexIngressName = "kubic-dex"
+
+	// the annotation with the class of the Ingress
+	ingressClassAnnotation = "kubernetes.io/ingress.class"
+
 	// the network policy name
 	dexNetworkPolicyName = "kubic-dex-networkpolicy"
 
@@ -56,48 +60,71 @@ const (
 	dexClusterRoleNameLDAP = "kubic:dex:ldap-administrators"
 
 	// DexClusterRoleNamePSP = "kubic-psp-dex"
-
-	// TODO: maybe configurable in "kubic-init.yaml"...
-	dexLDAPAdminGroupName = "Administrators"
-
-	// The image to use for Dex
-	dexDefaultImage = "registry.opensuse.org/devel/caasp/kubic-container/container/kubic/caasp-dex:2.7.1"
 )
 
 var (
-	// https://github.com/kubic-project/salt/blob/master/salt/addons/dex/manifests/05-serviceaccount.yaml
-	dexServiceAccount = corev1.ServiceAccount{
+	port389  = intstr.FromInt(389)
+	port443  = intstr.FromInt(443)
+	port636  = intstr.FromInt(636)
+	port6444 = intstr.FromInt(6444)
+	port53   = intstr.FromInt(53)
+	protoTCP = corev1.ProtocolTCP
+	protoUDP = corev1.ProtocolUDP
+)
+
+// getDexServiceName returns the name of the Service for a DexConfiguration
+func getDexServiceName(instance *kubicv1beta1.DexConfiguration) string {
+	return getInstanceName(instance, dexServiceName, "-")
+}
+
+// getDexServiceAccountName returns the name of the ServiceAccount for a DexConfiguration
+func getDexServiceAccountName(instance *kubicv1beta1.DexConfiguration) string {
+	return getInstanceName(instance, dexServiceAccountName, "-")
+}
+
+// getDexRBACName returns the name of a RBAC object for a DexConfiguration
+func getDexRBACName(instance *kubicv1beta1.DexConfiguration, name string) string {
+	return getInstanceName(instance, name, ":")
+}
+
+// https://github.com/kubic-project/salt/blob/master/salt/addons/dex/manifests/05-serviceaccount.yaml
+func newDexServiceAccount(instance *kubicv1beta1.DexConfiguration) *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      dexServiceAccountName,
-			Namespace: dexDefaultNamespace,
+			Name:      getDexServiceAccountName(instance),
+			Namespace: getNamespace(instance),
 			Labels: map[string]string{
 				"kubernetes.io/cluster-service": "true",
 			},
 		},
 	}
+}
 
-	dexRoles = []rbac.Role{
+func newDexRoles(instance *kubicv1beta1.DexConfiguration) []rbac.Role {
+	return []rbac.Role{
 		{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      dexRoleName,
-				Namespace: dexDefaultNamespace,
+				Name:      getDexRBACName(instance, dexRoleName),
+				Namespace: getNamespace(instance),
 			},
 			Rules: []rbac.PolicyRule{
 				{
 					APIGroups:     []string{""},
 					Resources:     []string{"services"},
-					ResourceNames: []string{dexServiceName},
+					ResourceNames: []string{getDexServiceName(instance)},
 					Verbs:         []string{"get"},
 				},
 			},
 		},
 	}
+}
 
-	// https://github.com/kubic-project/salt/blob/master/salt/addons/dex/manifests/05-clusterrole.yaml
-	dexClusterRoles = []rbac.ClusterRole{
+// https://github.com/kubic-project/salt/blob/master/salt/addons/dex/manifests/05-clusterrole.yaml
+func newDexClusterRoles(instance *kubicv1beta1.DexConfiguration) []rbac.ClusterRole {
+	return []rbac.ClusterRole{
 		{
 			ObjectMeta: metav1.ObjectMeta{
-				Name: dexClusterRoleName,
+				Name: getDexRBACName(instance, dexClusterRoleName),
 			},
 			Rules: []rbac.PolicyRule{
 				{
@@ -114,53 +141,38 @@ var (
 		},
 		{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      dexClusterRoleNameRead,
-				Namespace: dexDefaultNamespace,
+				Name:      getDexRBACName(instance, dexClusterRoleNameRead),
+				Namespace: getNamespace(instance),
 			},
 			Rules: []rbac.PolicyRule{
 				{
 					APIGroups:     []string{""},
 					Resources:     []string{"services"},
-					ResourceNames: []string{dexServiceAccountName},
+					ResourceNames: []string{getDexServiceName(instance)},
 					Verbs:         []string{"get"},
 				},
 			},
 		},
 	}
+}
 
-	// https://github.com/kubic-project/salt/blob/master/salt/addons/dex/manifests/10-clusterrolebinding.yaml
-	dexClusterRoleBindings = []rbac.ClusterRoleBinding{
+// https://github.com/kubic-project/salt/blob/master/salt/addons/dex/manifests/10-clusterrolebinding.yaml
+func newDexClusterRoleBindings(instance *kubicv1beta1.DexConfiguration) []rbac.ClusterRoleBinding {
+	bindings := []rbac.ClusterRoleBinding{
 		{
 			ObjectMeta: metav1.ObjectMeta{
-				Name: dexClusterRoleName,
+				Name: getDexRBACName(instance, dexClusterRoleName),
 			},
 			RoleRef: rbac.RoleRef{
 				APIGroup: rbac.GroupName,
 				Kind:     "ClusterRole",
-				Name:     dexClusterRoleName,
+				Name:     getDexRBACName(instance, dexClusterRoleName),
 			},
 			Subjects: []rbac.Subject{
 				{
 					Kind:      rbac.ServiceAccountKind,
-					Name:      dexServiceAccountName,
-					Namespace: dexDefaultNamespace,
-				},
-			},
-		},
-		{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: dexClusterRoleNameLDAP,
-			},
-			RoleRef: rbac.RoleRef{
-				APIGroup: rbac.GroupName,
-				Kind:     "ClusterRole",
-				Name:     dexcfg.DefaultClusterAdminRole,
-			},
-			Subjects: []rbac.Subject{
-				{
-					Kind:      rbac.GroupKind,
-					Name:      "ADMIN", // To be set...
-					Namespace: dexDefaultNamespace,
+					Name:      getDexServiceAccountName(instance),
+					Namespace: getNamespace(instance),
 				},
 			},
 		},
@@ -177,18 +189,43 @@ var (
 		// 		{
 		// 			Kind:      rbac.ServiceAccountKind,
 		// 			Name:      dexServiceAccountName,
-		// 			Namespace: dexDefaultNamespace,
+		// 			Namespace: getNamespace(instance),
 		// 		},
 		// 	},
 		// }
 	}
 
-	// https://github.com/kubic-project/salt/blob/master/salt/addons/dex/manifests/10-rolebinding.yaml
-	dexRoleBindings = []rbac.RoleBinding{
+	// only the main instance makes its administrators cluster administrators: the
+	// connectors of any other instance could be managed by someone else
+	if isMainInstance(instance) && len(instance.Spec.AdminGroup) > 0 {
+		bindings = append(bindings, rbac.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: getDexRBACName(instance, dexClusterRoleNameLDAP),
+			},
+			RoleRef: rbac.RoleRef{
+				APIGroup: rbac.GroupName,
+				Kind:     "ClusterRole",
+				Name:     dexcfg.DefaultClusterAdminRole,
+			},
+			Subjects: []rbac.Subject{
+				{
+					Kind:      rbac.GroupKind,
+					Name:      instance.Spec.AdminGroup,
+					Namespace: getNamespace(instance),
+				},
+			},
+		})
+	}
+	return bindings
+}
+
+// https://github.com/kubic-project/salt/blob/master/salt/addons/dex/manifests/10-rolebinding.yaml
+func newDexRoleBindings(instance *kubicv1beta1.DexConfiguration) []rbac.RoleBinding {
+	return []rbac.RoleBinding{
 		{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      dexClusterRoleName,
-				Namespace: dexDefaultNamespace,
+				Name:      getDexRBACName(instance, dexClusterRoleName),
+				Namespace: getNamespace(instance),
 			},
 			Subjects: []rbac.Subject{
 				{
@@ -204,22 +241,18 @@ var (
 			},
 			RoleRef: rbac.RoleRef{
 				Kind:     "Role",
-				Name:     dexClusterRoleNameRead,
+				Name:     getDexRBACName(instance, dexClusterRoleNameRead),
 				APIGroup: "rbac.authorization.k8s.io",
 			},
 		},
 	}
+}
 
-	port389  = intstr.FromInt(389)
-	port6444 = intstr.FromInt(6444)
-	port53   = intstr.FromInt(53)
-	protoTCP = corev1.ProtocolTCP
-	protoUDP = corev1.ProtocolUDP
-
-	dexNetworkPolicy = netv1.NetworkPolicy{
+func newDexNetworkPolicy(instance *kubicv1beta1.DexConfiguration) *netv1.NetworkPolicy {
+	return &netv1.NetworkPolicy{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      dexNetworkPolicyName,
-			Namespace: dexDefaultNamespace,
+			Name:      getInstanceName(instance, dexNetworkPolicyName, "-"),
+			Namespace: getNamespace(instance),
 		},
 		Spec: netv1.NetworkPolicySpec{
 			Egress: []netv1.NetworkPolicyEgressRule{
@@ -229,6 +262,16 @@ var (
 							Port:     &port389,
 							Protocol: &protoTCP,
 						},
+						{
+							// LDAPS
+							Port:     &port636,
+							Protocol: &protoTCP,
+						},
+						{
+							// HTTPS upstreams (GitHub, OIDC, GitLab, Bitbucket, Microsoft, Google)
+							Port:     &port443,
+							Protocol: &protoTCP,
+						},
 						{
 							Port:     &port6444,
 							Protocol: &protoTCP,
@@ -246,34 +289,118 @@ var (
 			},
 		},
 	}
+}
 
-	dexService = corev1.Service{
+func newDexService(instance *kubicv1beta1.DexConfiguration) *corev1.Service {
+	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      dexServiceName,
-			Namespace: dexDefaultNamespace,
+			Name:      getDexServiceName(instance),
+			Namespace: getNamespace(instance),
 			Labels: map[string]string{
 				"kubernetes.io/cluster-service": "true",
 				"kubernetes.io/name":            "Dex",
 			},
+			Annotations: instance.Spec.Expose.ServiceAnnotations,
 		},
 		Spec: corev1.ServiceSpec{
 			Type: corev1.ServiceTypeNodePort,
 			Ports: []corev1.ServicePort{{
 				Name:       dexServiceName,
 				Protocol:   corev1.ProtocolTCP,
-				Port:       int32(5556),
+				Port:       int32(dexServicePort),
 				TargetPort: intstr.FromString("https"),
 				NodePort:   0, // To be set...
 			},
 			},
 		},
 	}
-)
+
+	switch getExposeMode(instance) {
+	case kubicv1beta1.DexExposeLoadBalancer:
+		service.Spec.Type = corev1.ServiceTypeLoadBalancer
+		service.Spec.LoadBalancerIP = instance.Spec.Expose.LoadBalancerIP
+	case kubicv1beta1.DexExposeIngress:
+		service.Spec.Type = corev1.ServiceTypeClusterIP
+	}
+	return service
+}
+
+func newDexIngress(instance *kubicv1beta1.DexConfiguration) *extv1beta1.Ingress {
+	spec := instance.Spec.Expose.Ingress
+
+	annotations := map[string]string{}
+	for k, v := range spec.Annotations {
+		annotations[k] = v
+	}
+	if len(spec.Class) > 0 {
+		annotations[ingressClassAnnotation] = spec.Class
+	}
+
+	ingress := &extv1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        getInstanceName(instance, dexIngressName, "-"),
+			Namespace:   getNamespace(instance),
+			Annotations: annotations,
+		},
+		Spec: extv1beta1.IngressSpec{
+			Rules: []extv1beta1.IngressRule{
+				{
+					Host: spec.Host,
+					IngressRuleValue: extv1beta1.IngressRuleValue{
+						HTTP: &extv1beta1.HTTPIngressRuleValue{
+							Paths: []extv1beta1.HTTPIngressPath{
+								{
+									Path: "/",
+									Backend: extv1beta1.IngressBackend{
+										ServiceName: getDexServiceName(instance),
+										ServicePort: intstr.FromInt(dexServicePort),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if len(spec.TLSSecretName) > 0 {
+		ingress.Spec.TLS = []extv1beta1.IngressTLS{
+			{
+				Hosts:      []string{spec.Host},
+				SecretName: spec.TLSSecretName,
+			},
+		}
+	}
+	return ingress
+}
+
+// createDexNamespace creates the namespace where Dex is run, if it does not exist.
+// Note well that the namespace is not removed with the DexConfiguration, as it could
+// be used for other things.
+func createDexNamespace(cli clientset.Interface, instance *kubicv1beta1.DexConfiguration) error {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: getNamespace(instance),
+		},
+	}
+	if _, err := cli.CoreV1().Namespaces().Get(ns.GetName(), metav1.GetOptions{}); err == nil {
+		return nil
+	} else if !apierrors.IsNotFound(err) {
+		return err
+	}
+	glog.V(3).Infof("[kubic] creating Namespace '%s'", ns.GetName())
+	if _, err := cli.CoreV1().Namespaces().Create(ns); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
 
 // createOrUpdateDexServiceAccount creates the necessary serviceaccounts that kubeadm uses/might use, if they don't already exist.
-func createOrUpdateDexServiceAccount(cli clientset.Interface) error {
-	glog.V(3).Infof("[kubic] creating serviceAccount '%s'", dexServiceAccountName)
-	if err := apiclient.CreateOrUpdateServiceAccount(cli, &dexServiceAccount); err != nil && !apierrors.IsAlreadyExists(err) {
+func createOrUpdateDexServiceAccount(cli clientset.Interface, instance *kubicv1beta1.DexConfiguration) error {
+	sa := newDexServiceAccount(instance)
+	glog.V(3).Infof("[kubic] creating serviceAccount '%s'", sa.GetName())
+	if err := apiclient.CreateOrUpdateServiceAccount(cli, sa); err != nil && !apierrors.IsAlreadyExists(err) {
 		return err
 	}
 	return nil
@@ -281,14 +408,15 @@ func createOrUpdateDexServiceAccount(cli clientset.Interface) error {
 
 // deleteDexServiceAccount deletes the ServiceAccount created.
 // Note well that it will not fail if the ServiceAccount did not exist.
-func deleteDexServiceAccount(cli clientset.Interface) error {
-	glog.V(3).Infof("[kubic] deleting serviceAccount '%s'", dexServiceAccountName)
+func deleteDexServiceAccount(cli clientset.Interface, instance *kubicv1beta1.DexConfiguration) error {
+	sa := newDexServiceAccount(instance)
+	glog.V(3).Infof("[kubic] deleting serviceAccount '%s'", sa.GetName())
 
 	foregroundDelete := metav1.DeletePropagationForeground
 	deleteOptions := &metav1.DeleteOptions{
 		PropagationPolicy: &foregroundDelete,
 	}
-	err := cli.CoreV1().ServiceAccounts(dexServiceAccount.GetNamespace()).Delete(dexServiceAccount.GetName(), deleteOptions)
+	err := cli.CoreV1().ServiceAccounts(sa.GetNamespace()).Delete(sa.GetName(), deleteOptions)
 	if err != nil && !apierrors.IsNotFound(err) {
 		return err
 	}
@@ -296,12 +424,12 @@ func deleteDexServiceAccount(cli clientset.Interface) error {
 }
 
 // createorUpdateDexRBACRules creates the essential RBAC rules for a minimally set-up cluster
-func createorUpdateDexRBACRules(cli clientset.Interface, dexcfg *kubicv1beta1.DexConfiguration) error {
+func createorUpdateDexRBACRules(cli clientset.Interface, instance *kubicv1beta1.DexConfiguration) error {
 	glog.V(3).Infof("[kubic] creating RBAC rules for Dex")
 
 	cliREST := cli.Discovery().RESTClient()
 
-	for _, cr := range dexClusterRoles {
+	for _, cr := range newDexClusterRoles(instance) {
 		glog.V(3).Infof("[kubic] creating ClusterRole '%s'", cr.GetName())
 		if err := apiclient.CreateOrUpdateClusterRole(cli, &cr); err != nil && !apierrors.IsAlreadyExists(err) {
 			return err
@@ -311,7 +439,7 @@ func createorUpdateDexRBACRules(cli clientset.Interface, dexcfg *kubicv1beta1.De
 		}
 	}
 
-	for _, r := range dexRoles {
+	for _, r := range newDexRoles(instance) {
 		glog.V(3).Infof("[kubic] creating Role '%s'", r.GetName())
 		if err := apiclient.CreateOrUpdateRole(cli, &r); err != nil && !apierrors.IsAlreadyExists(err) {
 			return err
@@ -321,19 +449,11 @@ func createorUpdateDexRBACRules(cli clientset.Interface, dexcfg *kubicv1beta1.De
 		}
 	}
 
-	for _, crb := range dexClusterRoleBindings {
+	if isMainInstance(instance) {
+		glog.V(3).Infof("[kubic] using '%s' as Admin group", instance.Spec.AdminGroup)
+	}
+	for _, crb := range newDexClusterRoleBindings(instance) {
 		glog.V(3).Infof("[kubic] creating ClusterRoleBindings '%s'", crb.GetName())
-
-		// set the ADMIN group in the ClusterRoleBindings by detecting the "ADMIN" name
-		if crb.Subjects[0].Name == "ADMIN" {
-			if len(dexcfg.Spec.AdminGroup) > 0 {
-				glog.V(3).Infof("[kubic] using '%s' as Admin group", dexcfg.Spec.AdminGroup)
-				crb.Subjects[0].Name = dexcfg.Spec.AdminGroup
-			} else {
-				crb.Subjects[0].Name = dexLDAPAdminGroupName
-			}
-		}
-
 		if err := apiclient.CreateOrUpdateClusterRoleBinding(cli, &crb); err != nil && !apierrors.IsAlreadyExists(err) {
 			return err
 		}
@@ -342,7 +462,7 @@ func createorUpdateDexRBACRules(cli clientset.Interface, dexcfg *kubicv1beta1.De
 		}
 	}
 
-	for _, rb := range dexRoleBindings {
+	for _, rb := range newDexRoleBindings(instance) {
 		glog.V(3).Infof("[kubic] creating RoleBindings '%s'", rb.GetName())
 
 		if err := apiclient.CreateOrUpdateRoleBinding(cli, &rb); err != nil && !apierrors.IsAlreadyExists(err) {
@@ -360,7 +480,7 @@ func createorUpdateDexRBACRules(cli clientset.Interface, dexcfg *kubicv1beta1.De
 // Note well that it will not fail if they did not exist.
 // Deletion is performed in foreground mode; i.e. it blocks until/makes sure
 // all the resources are deleted.
-func deleteDexRBACRules(cli clientset.Interface) error {
+func deleteDexRBACRules(cli clientset.Interface, instance *kubicv1beta1.DexConfiguration) error {
 	glog.V(3).Infoln("[kubic] deleting RBAC rules for Dex")
 
 	foregroundDelete := metav1.DeletePropagationForeground
@@ -368,7 +488,7 @@ func deleteDexRBACRules(cli clientset.Interface) error {
 		PropagationPolicy: &foregroundDelete,
 	}
 
-	for _, crb := range dexClusterRoleBindings {
+	for _, crb := range newDexClusterRoleBindings(instance) {
 		glog.V(3).Infof("[kubic] deleting ClusterRoleBinding %s", crb.GetName())
 		if err := cli.RbacV1().ClusterRoleBindings().Delete(crb.GetName(), deleteOptions); err != nil && !apierrors.IsNotFound(err) {
 			return err
@@ -376,7 +496,7 @@ func deleteDexRBACRules(cli clientset.Interface) error {
 		// TODO: we should wait for the object to disappear...
 	}
 
-	for _, rb := range dexRoleBindings {
+	for _, rb := range newDexRoleBindings(instance) {
 		glog.V(3).Infof("[kubic] deleting RoleBinding %s", rb.GetName())
 		if err := cli.RbacV1().RoleBindings(rb.GetNamespace()).Delete(rb.GetName(), deleteOptions); err != nil && !apierrors.IsNotFound(err) {
 			return err
@@ -384,7 +504,7 @@ func deleteDexRBACRules(cli clientset.Interface) error {
 		// TODO: we should wait for the object to disappear...
 	}
 
-	for _, cr := range dexClusterRoles {
+	for _, cr := range newDexClusterRoles(instance) {
 		glog.V(3).Infof("[kubic] deleting ClusterRole '%s'", cr.GetName())
 		if err := cli.RbacV1().ClusterRoles().Delete(cr.GetName(), deleteOptions); err != nil && !apierrors.IsNotFound(err) {
 			return err
@@ -392,7 +512,7 @@ func deleteDexRBACRules(cli clientset.Interface) error {
 		// TODO: we should wait for the object to disappear...
 	}
 
-	for _, r := range dexRoles {
+	for _, r := range newDexRoles(instance) {
 		glog.V(3).Infof("[kubic] deleting Role '%s'", r.GetName())
 		if err := cli.RbacV1().Roles(r.GetNamespace()).Delete(r.GetName(), deleteOptions); err != nil && !apierrors.IsNotFound(err) {
 			return err
@@ -403,60 +523,94 @@ func deleteDexRBACRules(cli clientset.Interface) error {
 	return nil
 }
 
-func createOrUpdateDexService(cli clientset.Interface, dexDeployName string, nodeport int) error {
+func createOrUpdateDexService(cli clientset.Interface, instance *kubicv1beta1.DexConfiguration, dexDeployName string) error {
 	// try to replicate the old behaviour in
 	// https://github.com/kubic-project/salt/blob/master/salt/addons/dex/manifests/30-network-policy.yaml
 
 	cliREST := cli.Discovery().RESTClient()
 
-	service := dexService.DeepCopy()
-	service.Spec.Ports[0].NodePort = int32(nodeport)
+	service := newDexService(instance)
+	if service.Spec.Type == corev1.ServiceTypeNodePort {
+		service.Spec.Ports[0].NodePort = int32(instance.Spec.NodePort)
+	}
 	service.Spec.Selector = map[string]string{
 		"app": dexDeployName,
 	}
 
 	glog.V(3).Infof("[kubic] creating Service '%s' (nodeport=%d)",
 		service.GetName(), service.Spec.Ports[0].NodePort)
-	if _, err := kubicclient.CreateOrUpdateService(cli, service); err != nil && !apierrors.IsAlreadyExists(err) {
+	current, err := kubicclient.CreateOrUpdateService(cli, service)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
 		return err
 	} else if err := kubicclient.WaitForObject(cliREST, service); err != nil {
 		return err
 	}
+
+	// record the NodePort allocated by Kubernetes, so it is used in the issuer
+	if service.Spec.Type == corev1.ServiceTypeNodePort && instance.Spec.NodePort == 0 && current != nil {
+		instance.Spec.NodePort = int(current.Spec.Ports[0].NodePort)
+		glog.V(3).Infof("[kubic] NodePort %d allocated for Service '%s'", instance.Spec.NodePort, service.GetName())
+	}
+	return nil
+}
+
+func deleteDexService(cli clientset.Interface, instance *kubicv1beta1.DexConfiguration) error {
+	service := newDexService(instance)
+	glog.V(3).Infof("[kubic] removing Service '%s'", service.GetName())
+	if err := kubicclient.DeleteServiceForeground(cli, service); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func createOrUpdateDexIngress(cli clientset.Interface, instance *kubicv1beta1.DexConfiguration) error {
+	cliREST := cli.Discovery().RESTClient()
+
+	ingress := newDexIngress(instance)
+	glog.V(3).Infof("[kubic] creating Ingress '%s' (host=%s)", ingress.GetName(), instance.Spec.Expose.Ingress.Host)
+	if _, err := kubicclient.CreateOrUpdateIngress(cli, ingress); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	} else if err := kubicclient.WaitForObject(cliREST, ingress); err != nil {
+		return err
+	}
 	return nil
 }
 
-func deleteDexService(cli clientset.Interface) error {
-	glog.V(3).Infof("[kubic] removing Service '%s'", dexService.GetName())
-	if err := kubicclient.DeleteServiceForeground(cli, &dexService); err != nil && !apierrors.IsNotFound(err) {
+func deleteDexIngress(cli clientset.Interface, instance *kubicv1beta1.DexConfiguration) error {
+	ingress := newDexIngress(instance)
+	glog.V(5).Infof("[kubic] removing Ingress '%s'", ingress.GetName())
+	if err := kubicclient.DeleteIngressForeground(cli, ingress); err != nil && !apierrors.IsNotFound(err) {
 		return err
 	}
 	return nil
 }
 
-func createOrUpdateDexNetworkPolicy(cli clientset.Interface, dexDeployName string) error {
+func createOrUpdateDexNetworkPolicy(cli clientset.Interface, instance *kubicv1beta1.DexConfiguration, dexDeployName string) error {
 	// try to replicate the old behaviour in
 	// https://github.com/kubic-project/salt/blob/master/salt/addons/dex/manifests/30-network-policy.yaml
 
 	cliREST := cli.Discovery().RESTClient()
 
-	dexNetworkPolicy.Spec.PodSelector = metav1.LabelSelector{
+	networkPolicy := newDexNetworkPolicy(instance)
+	networkPolicy.Spec.PodSelector = metav1.LabelSelector{
 		MatchLabels: map[string]string{
 			"app": dexDeployName,
 		},
 	}
 
-	glog.V(3).Infof("[kubic] creating NetworkPolicy '%s'", dexNetworkPolicy.GetName())
-	if _, err := kubicclient.CreateOrUpdateNetworkPolicy(cli, &dexNetworkPolicy); err != nil && !apierrors.IsAlreadyExists(err) {
+	glog.V(3).Infof("[kubic] creating NetworkPolicy '%s'", networkPolicy.GetName())
+	if _, err := kubicclient.CreateOrUpdateNetworkPolicy(cli, networkPolicy); err != nil && !apierrors.IsAlreadyExists(err) {
 		return err
-	} else if err := kubicclient.WaitForObject(cliREST, &dexNetworkPolicy); err != nil {
+	} else if err := kubicclient.WaitForObject(cliREST, networkPolicy); err != nil {
 		return err
 	}
 	return nil
 }
 
-func deleteNetworkPolicy(cli clientset.Interface) error {
-	glog.V(3).Infof("[kubic] removing NetworkPolicy '%s'", dexNetworkPolicy.GetName())
-	if err := kubicclient.DeleteNetworkPolicyForeground(cli, &dexNetworkPolicy); err != nil && !apierrors.IsNotFound(err) {
+func deleteNetworkPolicy(cli clientset.Interface, instance *kubicv1beta1.DexConfiguration) error {
+	networkPolicy := newDexNetworkPolicy(instance)
+	glog.V(3).Infof("[kubic] removing NetworkPolicy '%s'", networkPolicy.GetName())
+	if err := kubicclient.DeleteNetworkPolicyForeground(cli, networkPolicy); err != nil && !apierrors.IsNotFound(err) {
 		return err
 	}
 	return nil
@@ -0,0 +1,91 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dex
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
+	dexcfg "github.com/kubic-project/dex-operator/pkg/config"
+)
+
+// allowsEgress returns true if the network policy allows TCP connections to `port`
+func allowsEgress(policy *netv1.NetworkPolicy, port int) bool {
+	for _, rule := range policy.Spec.Egress {
+		for _, p := range rule.Ports {
+			if p.Port != nil && p.Port.IntValue() == port &&
+				(p.Protocol == nil || *p.Protocol == corev1.ProtocolTCP) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestDexNetworkPolicy(t *testing.T) {
+	instance := &kubicv1beta1.DexConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "dex"},
+	}
+	policy := newDexNetworkPolicy(instance)
+
+	// GitHub, OIDC, GitLab, Bitbucket, Microsoft and Google connectors need HTTPS
+	if !allowsEgress(policy, 443) {
+		t.Errorf("egress to HTTPS upstreams is not allowed by the network policy")
+	}
+	// LDAP connectors, with and without TLS
+	for _, port := range []int{389, 636} {
+		if !allowsEgress(policy, port) {
+			t.Errorf("egress to LDAP servers in port %d is not allowed by the network policy", port)
+		}
+	}
+	// DNS resolution
+	if !allowsEgress(policy, 53) {
+		t.Errorf("egress to DNS servers is not allowed by the network policy")
+	}
+}
+
+func TestDexClusterRoleBindings(t *testing.T) {
+	isClusterAdmin := func(instance *kubicv1beta1.DexConfiguration) bool {
+		for _, crb := range newDexClusterRoleBindings(instance) {
+			if crb.RoleRef.Name == dexcfg.DefaultClusterAdminRole {
+				return true
+			}
+		}
+		return false
+	}
+
+	mainInstance := &kubicv1beta1.DexConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: dexcfg.DefaultConfigurationName},
+		Spec:       kubicv1beta1.DexConfigurationSpec{AdminGroup: dexcfg.DefaultAdminGroup},
+	}
+	if !isClusterAdmin(mainInstance) {
+		t.Errorf("the admin group of the main instance is not bound to '%s'", dexcfg.DefaultClusterAdminRole)
+	}
+
+	tenant := &kubicv1beta1.DexConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant-a"},
+		Spec:       kubicv1beta1.DexConfigurationSpec{AdminGroup: dexcfg.DefaultAdminGroup},
+	}
+	if isClusterAdmin(tenant) {
+		t.Errorf("the admin group of instance '%s' is bound to '%s'", tenant.GetName(), dexcfg.DefaultClusterAdminRole)
+	}
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dex
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
+)
+
+// getCondition returns the condition of type `t`, or nil if it is not present
+func getCondition(conditions []kubicv1beta1.Condition, t kubicv1beta1.ConditionType) *kubicv1beta1.Condition {
+	for i := range conditions {
+		if conditions[i].Type == t {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// setCondition sets a condition in a list of conditions, returning the new list.
+// The transition time is only updated when the status of the condition changes.
+func setCondition(conditions []kubicv1beta1.Condition, t kubicv1beta1.ConditionType,
+	status corev1.ConditionStatus, reason, message string) []kubicv1beta1.Condition {
+
+	if current := getCondition(conditions, t); current != nil {
+		if current.Status != status {
+			current.LastTransitionTime = metav1.Now()
+		}
+		current.Status = status
+		current.Reason = reason
+		current.Message = message
+		return conditions
+	}
+
+	return append(conditions, kubicv1beta1.Condition{
+		Type:               t,
+		Status:             status,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// isConditionTrue returns true if the condition of type `t` is present and its status is True
+func isConditionTrue(conditions []kubicv1beta1.Condition, t kubicv1beta1.ConditionType) bool {
+	c := getCondition(conditions, t)
+	return c != nil && c.Status == corev1.ConditionTrue
+}
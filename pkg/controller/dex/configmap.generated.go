@@ -29,7 +29,7 @@ metadata:
   namespace: {{ .DexNamespace }}
 data:
   {{ .DexConfigMapFilename | basename }}: |
-    issuer: "https://{{ .DexAddress }}:{{ .DexPort }}"
+    issuer: "{{ .DexIssuer }}"
 
     storage:
       type: kubernetes
@@ -44,7 +44,7 @@ data:
       dir: /usr/share/caasp-dex/web
       theme: caasp
 
-{{- if .LDAPConnectors }}
+{{- if .NumConnectors }}
     connectors:
   {{- range $Con := .LDAPConnectors }}
     - type: ldap
@@ -63,7 +63,7 @@ data:
         startTLS: {{ $Con.Spec.StartTLS }}
     {{- end }}
 
-	  {{- if and $Con.Spec.BindDN $Con.Spec.BindPW }}
+	  {{- if and $Con.Spec.BindDN $Con.BindPW }}
         # The DN and password for an application service account. The connector uses
         # these credentials to search for users and groups. Not required if the LDAP
         # server provides access for anonymous auth.
@@ -72,7 +72,7 @@ data:
         # bindDN: uid=seviceaccount,cn=users,dc=example,dc=com
         # bindPW: password
         bindDN: {{ $Con.Spec.BindDN }}
-        bindPW: {{ $Con.Spec.BindPW }}
+        bindPW: {{ $Con.BindPW }}
     {{- else }}
         # bindDN and bindPW not present; anonymous bind will be used
     {{- end }}
@@ -81,16 +81,9 @@ data:
         usernamePrompt: {{ $Con.Spec.UsernamePrompt }}
 	  {{- end }}
 
-	  {{- if $Con.Spec.RootCAData }}
+	  {{- if $Con.RootCAData }}
         # A raw certificate file can also be provided inline.
-        rootCAData: {{ $Con.Spec.RootCAData | replace "\n" "" | base64encode }}
-      {{- else }}
-        # Path to a trusted root certificate file.
-        # The CA certificate bundle is automatically mounted into pods using
-        # the default service account at this path. If you are not using the default
-        # service account, we should build a configmap containing
-        # the certificate bundle that you have access to use...
-        rootCA: /var/run/secrets/kubernetes.io/serviceaccount/ca.crt
+        rootCAData: {{ $Con.RootCAData | base64encode }}
       {{- end }}
     {{- if $Con.Spec.User.BaseDN }}
         userSearch:
@@ -139,6 +132,272 @@ data:
 
     {{- end }}
   {{- end }}
+
+  {{- range $Con := .GitHubConnectors }}
+    - type: github
+      id: {{ $Con.Spec.ID }}
+      name: {{ $Con.Spec.Name }}
+      config:
+        # Credentials can be string literals or pulled from the environment.
+        clientID: {{ $Con.Spec.ClientID }}
+        clientSecret: {{ $Con.ClientSecret }}
+      {{- if $Con.Spec.RedirectURI }}
+        redirectURI: {{ $Con.Spec.RedirectURI }}
+      {{- else }}
+        redirectURI: {{ $.DexIssuer }}/callback
+      {{- end }}
+
+    {{- if $Con.Spec.Orgs }}
+        # Users must be members of at least one of these organizations (and,
+        # optionally, of one of the teams in the organization).
+        orgs:
+      {{- range $Org := $Con.Spec.Orgs }}
+        - name: {{ $Org.Name }}
+        {{- if $Org.Teams }}
+          teams:
+          {{- range $Team := $Org.Teams }}
+          - {{ $Team }}
+          {{- end }}
+        {{- end }}
+      {{- end }}
+    {{- end }}
+
+    {{- if $Con.Spec.LoadAllGroups }}
+        # Load all the teams the user is a member of as groups.
+        loadAllGroups: {{ $Con.Spec.LoadAllGroups }}
+    {{- end }}
+
+    {{- if $Con.Spec.TeamNameField }}
+        # The field used as the group name: "name", "slug" or "both".
+        teamNameField: {{ $Con.Spec.TeamNameField }}
+    {{- end }}
+  {{- end }}
+
+  {{- range $Con := .OIDCConnectors }}
+    - type: oidc
+      id: {{ $Con.Spec.ID }}
+      name: {{ $Con.Spec.Name }}
+      config:
+        # Canonical URL of the provider, also used for configuration discovery.
+        issuer: {{ $Con.Spec.Issuer }}
+
+        clientID: {{ $Con.Spec.ClientID }}
+        clientSecret: {{ $Con.ClientSecret }}
+      {{- if $Con.Spec.RedirectURI }}
+        redirectURI: {{ $Con.Spec.RedirectURI }}
+      {{- else }}
+        redirectURI: {{ $.DexIssuer }}/callback
+      {{- end }}
+
+    {{- if $Con.Spec.Scopes }}
+        # List of additional scopes to request in token response.
+        scopes:
+      {{- range $Scope := $Con.Spec.Scopes }}
+        - {{ $Scope }}
+      {{- end }}
+    {{- end }}
+
+    {{- if $Con.Spec.InsecureSkipEmailVerified }}
+        # Some providers return claims without "email_verified", when they had no usage of emails verification
+        insecureSkipEmailVerified: {{ $Con.Spec.InsecureSkipEmailVerified }}
+    {{- end }}
+
+    {{- if $Con.Spec.InsecureEnableGroups }}
+        # Groups claims (like the rest of oidc claims through dex) only refresh when the id token is refreshed
+        insecureEnableGroups: {{ $Con.Spec.InsecureEnableGroups }}
+    {{- end }}
+
+    {{- if $Con.Spec.GetUserInfo }}
+        # When enabled, the OpenID Connector will query the UserInfo endpoint for additional claims.
+        getUserInfo: {{ $Con.Spec.GetUserInfo }}
+    {{- end }}
+
+    {{- if $Con.Spec.UserIDKey }}
+        userIDKey: {{ $Con.Spec.UserIDKey }}
+    {{- end }}
+
+    {{- if $Con.Spec.UserNameKey }}
+        userNameKey: {{ $Con.Spec.UserNameKey }}
+    {{- end }}
+
+    {{- with $Con.Spec.ClaimMapping }}
+      {{- if or .PreferredUsernameKey .EmailKey .GroupsKey }}
+        # Some providers return non-standard claims: map them to the standard ones.
+        claimMapping:
+        {{- if .PreferredUsernameKey }}
+          preferred_username: {{ .PreferredUsernameKey }}
+        {{- end }}
+        {{- if .EmailKey }}
+          email: {{ .EmailKey }}
+        {{- end }}
+        {{- if .GroupsKey }}
+          groups: {{ .GroupsKey }}
+        {{- end }}
+      {{- end }}
+    {{- end }}
+  {{- end }}
+
+  {{- range $Con := .SAMLConnectors }}
+    - type: saml
+      id: {{ $Con.Spec.ID }}
+      name: {{ $Con.Spec.Name }}
+      config:
+        # SSO URL used for POST value.
+        ssoURL: {{ $Con.Spec.SSOURL }}
+
+        # CA used for validating the signature of the SAML response.
+        caData: {{ $Con.CAData | base64encode }}
+
+      {{- if $Con.Spec.RedirectURI }}
+        redirectURI: {{ $Con.Spec.RedirectURI }}
+      {{- else }}
+        redirectURI: {{ $.DexIssuer }}/callback
+      {{- end }}
+
+        # Name of attributes in the returned assertions to map to ID token claims.
+        usernameAttr: {{ $Con.Spec.UsernameAttr }}
+        emailAttr: {{ $Con.Spec.EmailAttr }}
+      {{- if $Con.Spec.GroupsAttr }}
+        groupsAttr: {{ $Con.Spec.GroupsAttr }}
+      {{- end }}
+
+      {{- if $Con.Spec.EntityIssuer }}
+        # Issuer value for AuthnRequest
+        entityIssuer: {{ $Con.Spec.EntityIssuer }}
+      {{- end }}
+
+      {{- if $Con.Spec.SSOIssuer }}
+        # Issuer value expected in the SAML response
+        ssoIssuer: {{ $Con.Spec.SSOIssuer }}
+      {{- end }}
+
+      {{- if $Con.Spec.NameIDPolicyFormat }}
+        # Requested format of the NameID.
+        nameIDPolicyFormat: {{ $Con.Spec.NameIDPolicyFormat }}
+      {{- end }}
+  {{- end }}
+
+  {{- range $Con := .GitLabConnectors }}
+    - type: gitlab
+      id: {{ $Con.Spec.ID }}
+      name: {{ $Con.Spec.Name }}
+      config:
+      {{- if $Con.Spec.BaseURL }}
+        # optional, default = https://gitlab.com
+        baseURL: {{ $Con.Spec.BaseURL }}
+      {{- end }}
+
+        # Credentials can be string literals or pulled from the environment.
+        clientID: {{ $Con.Spec.ClientID }}
+        clientSecret: {{ $Con.ClientSecret }}
+      {{- if $Con.Spec.RedirectURI }}
+        redirectURI: {{ $Con.Spec.RedirectURI }}
+      {{- else }}
+        redirectURI: {{ $.DexIssuer }}/callback
+      {{- end }}
+
+    {{- if $Con.Spec.Groups }}
+        # Only users in these groups will be allowed.
+        groups:
+      {{- range $Group := $Con.Spec.Groups }}
+        - {{ $Group }}
+      {{- end }}
+    {{- end }}
+
+    {{- if $Con.Spec.UseLoginAsID }}
+        # Use the username as the ID instead of the internal GitLab ID.
+        useLoginAsID: {{ $Con.Spec.UseLoginAsID }}
+    {{- end }}
+  {{- end }}
+
+  {{- range $Con := .BitbucketConnectors }}
+    - type: bitbucket-cloud
+      id: {{ $Con.Spec.ID }}
+      name: {{ $Con.Spec.Name }}
+      config:
+        # Credentials can be string literals or pulled from the environment.
+        clientID: {{ $Con.Spec.ClientID }}
+        clientSecret: {{ $Con.ClientSecret }}
+      {{- if $Con.Spec.RedirectURI }}
+        redirectURI: {{ $Con.Spec.RedirectURI }}
+      {{- else }}
+        redirectURI: {{ $.DexIssuer }}/callback
+      {{- end }}
+
+    {{- if $Con.Spec.Teams }}
+        # Only users in these teams will be allowed.
+        teams:
+      {{- range $Team := $Con.Spec.Teams }}
+        - {{ $Team }}
+      {{- end }}
+    {{- end }}
+  {{- end }}
+
+  {{- range $Con := .MicrosoftConnectors }}
+    - type: microsoft
+      id: {{ $Con.Spec.ID }}
+      name: {{ $Con.Spec.Name }}
+      config:
+        # The client secret is provided in an environment variable.
+        clientID: {{ $Con.Spec.ClientID }}
+        clientSecret: {{ $Con.ClientSecret }}
+      {{- if $Con.Spec.RedirectURI }}
+        redirectURI: {{ $Con.Spec.RedirectURI }}
+      {{- else }}
+        redirectURI: {{ $.DexIssuer }}/callback
+      {{- end }}
+
+    {{- if $Con.Spec.Tenant }}
+        # Restrict the users to this tenant.
+        tenant: {{ $Con.Spec.Tenant }}
+    {{- end }}
+
+    {{- if $Con.Spec.Groups }}
+        # Only users in these groups will be allowed.
+        groups:
+      {{- range $Group := $Con.Spec.Groups }}
+        - {{ $Group }}
+      {{- end }}
+    {{- end }}
+
+    {{- if $Con.Spec.OnlySecurityGroups }}
+        onlySecurityGroups: {{ $Con.Spec.OnlySecurityGroups }}
+    {{- end }}
+
+    {{- if $Con.Spec.GroupNameFormat }}
+        groupNameFormat: {{ $Con.Spec.GroupNameFormat }}
+    {{- end }}
+  {{- end }}
+
+  {{- range $Con := .GoogleConnectors }}
+    - type: google
+      id: {{ $Con.Spec.ID }}
+      name: {{ $Con.Spec.Name }}
+      config:
+        # The client secret is provided in an environment variable.
+        clientID: {{ $Con.Spec.ClientID }}
+        clientSecret: {{ $Con.ClientSecret }}
+      {{- if $Con.Spec.RedirectURI }}
+        redirectURI: {{ $Con.Spec.RedirectURI }}
+      {{- else }}
+        redirectURI: {{ $.DexIssuer }}/callback
+      {{- end }}
+
+    {{- if $Con.Spec.HostedDomains }}
+        # Only users from these domains will be allowed.
+        hostedDomains:
+      {{- range $Domain := $Con.Spec.HostedDomains }}
+        - {{ $Domain }}
+      {{- end }}
+    {{- end }}
+
+    {{- if $Con.ServiceAccountFilePath }}
+        # The service account (mounted from the credentials Secret) and the
+        # admin to impersonate when fetching groups.
+        serviceAccountFilePath: {{ $Con.ServiceAccountFilePath }}
+        adminEmail: {{ $Con.Spec.AdminEmail }}
+    {{- end }}
+  {{- end }}
 {{- end }}
 
     oauth2:
@@ -37,29 +37,38 @@ import (
 	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
 	dexcfg "github.com/kubic-project/dex-operator/pkg/config"
 	"github.com/kubic-project/dex-operator/pkg/crypto"
-	dexnet "github.com/kubic-project/dex-operator/pkg/net"
 	"github.com/kubic-project/dex-operator/pkg/util"
 )
 
 // ConfigMap struct
+// The Dex configuration is always rendered as a ConfigMap, but it can be stored
+// in the cluster as a Secret (the default for new installations), as it
+// contains some sensitive information like the static clients passwords.
 type ConfigMap struct {
 	instance *kubicv1beta1.DexConfiguration
 
 	FileName string
 
-	current    *corev1.ConfigMap
-	generated  *corev1.ConfigMap
+	// Kind of object where the configuration is stored (ConfigMap or Secret)
+	Kind string
+
+	current         *corev1.ConfigMap
+	generated       *corev1.ConfigMap
+	currentSecret   *corev1.Secret
+	generatedSecret *corev1.Secret
+
+	// an object where the configuration was stored before, with a different Kind
+	stale      metav1.Object
 	reconciler *ReconcileDexConfiguration
 }
 
 // NewDexConfigMapFor returns a new dex.ConfigMap
 func NewDexConfigMapFor(instance *kubicv1beta1.DexConfiguration, reconciler *ReconcileDexConfiguration) (*ConfigMap, error) {
 	cm := &ConfigMap{
-		instance,
-		dexcfg.DefaultConfigMapFilename,
-		nil,
-		nil,
-		reconciler,
+		instance:   instance,
+		FileName:   dexcfg.DefaultConfigMapFilename,
+		Kind:       getConfigKind(instance),
+		reconciler: reconciler,
 	}
 
 	if err := cm.GetFrom(instance); err != nil {
@@ -68,77 +77,121 @@ func NewDexConfigMapFor(instance *kubicv1beta1.DexConfiguration, reconciler *Rec
 	return cm, nil
 }
 
+// getConfigKind returns the kind of object where the Dex configuration must be stored
+func getConfigKind(instance *kubicv1beta1.DexConfiguration) string {
+	switch instance.Spec.ConfigStorage {
+	case kindConfigMap, kindSecret:
+		return instance.Spec.ConfigStorage
+	}
+
+	// keep using the same kind of object in existing installations
+	if kind := getCurrentConfigKind(instance); len(kind) > 0 {
+		return kind
+	}
+	return kindSecret
+}
+
+// getCurrentConfigKind returns the kind of object where the Dex configuration is currently
+// stored, or an empty string if there is no configuration in the cluster
+func getCurrentConfigKind(instance *kubicv1beta1.DexConfiguration) string {
+	if len(instance.Status.Config) == 0 {
+		return ""
+	}
+	if len(instance.Status.ConfigKind) == 0 {
+		// installations previous to the introduction of ConfigKind were always ConfigMaps
+		return kindConfigMap
+	}
+	return instance.Status.ConfigKind
+}
+
 // GetFrom obtains the current configmap fromm the ConfigMap specified in the instance.Status
 func (config *ConfigMap) GetFrom(instance *kubicv1beta1.DexConfiguration) error {
 	var err error
 	var name, namespace string
 
 	// Try to the get current ConfigMap from the data in the instance.Status.Deployment
-	if len(instance.Status.Config) > 0 {
+	kind := getCurrentConfigKind(instance)
+	if len(kind) > 0 {
 		nname := util.StringToNamespacedName(instance.Status.Config)
 		name, namespace = nname.Name, nname.Namespace
 	} else {
+		kind = config.Kind
 		name, namespace = config.GetName(), config.GetNamespace()
 	}
 
-	// try to get the current ConfigMap
-	config.current, err = config.reconciler.Clientset.Core().ConfigMaps(namespace).Get(name, metav1.GetOptions{})
+	// try to get the current ConfigMap (or Secret)
+	var current metav1.Object
+	switch kind {
+	case kindSecret:
+		config.currentSecret, err = config.reconciler.Clientset.Core().Secrets(namespace).Get(name, metav1.GetOptions{})
+		current = config.currentSecret
+	default:
+		config.current, err = config.reconciler.Clientset.Core().ConfigMaps(namespace).Get(name, metav1.GetOptions{})
+		current = config.current
+	}
 	if err != nil {
-		config.current = nil
+		config.current, config.currentSecret = nil, nil
 		if !apierrors.IsNotFound(err) {
 			return err
 		}
-	} else {
-		glog.V(3).Infof("[kubic] there is an existing ConfigMap for Dex")
+		return nil
+	}
+	glog.V(3).Infof("[kubic] there is an existing %s for Dex", kind)
+
+	if kind != config.Kind {
+		glog.V(3).Infof("[kubic] Dex configuration will be moved from %s '%s' to a %s",
+			kind, util.NamespacedObjToString(current), config.Kind)
+		config.stale = current
+		config.current, config.currentSecret = nil, nil
 	}
 
 	return nil
 }
 
-// CreateLocal generates a local ConfigMap instance. Note well that this instance is
-// not published to the apiserver: users must use `CreateOrUpdate()` for doing that.
-func (config *ConfigMap) CreateLocal(connectors []kubicv1beta1.LDAPConnector,
+// CreateLocal generates a local ConfigMap instance, for Dex running with the `dexIssuer` URL.
+// Note well that this instance is not published to the apiserver: users must use
+// `CreateOrUpdate()` for doing that.
+func (config *ConfigMap) CreateLocal(dexIssuer string, connectors Connectors,
 	staticClientsPasswords StaticClientsPasswords) error {
 
 	var err error
-	var dexAddress string
 
 	glog.V(3).Infoln("[kubic] generating local ConfigMap for Dex")
-	if len(config.instance.Spec.Names) > 0 {
-		dexAddress = config.instance.Spec.Names[0]
-	} else {
-		dexAddress, err = dexnet.GetPublicAPIAddress()
-		if err != nil {
-			return err
-		}
-	}
-
-	dexPort := dexcfg.DefaultNodePort
-	if config.instance.Spec.NodePort != 0 {
-		dexPort = config.instance.Spec.NodePort
-	}
-
-	glog.V(3).Infof("[kubic] Dex issuer: https://%s:%d", dexAddress, dexPort)
+	glog.V(3).Infof("[kubic] Dex issuer: %s", dexIssuer)
 	replacements := struct {
 		DexConfigMapFilename string
 		DexName              string
 		DexNamespace         string
-		DexAddress           string
-		DexPort              int
+		DexIssuer            string
 		DexSharedPasswords   map[string]crypto.SharedPassword
 		DexCertsDir          string
 		StaticClients        []kubicv1beta1.DexStaticClient
-		LDAPConnectors       []kubicv1beta1.LDAPConnector
+		NumConnectors        int
+		LDAPConnectors       []LDAPConnector
+		GitHubConnectors     []GitHubConnector
+		OIDCConnectors       []OIDCConnector
+		SAMLConnectors       []SAMLConnector
+		GitLabConnectors     []GitLabConnector
+		BitbucketConnectors  []BitbucketCloudConnector
+		MicrosoftConnectors  []MicrosoftConnector
+		GoogleConnectors     []GoogleConnector
 	}{
 		config.FileName,
 		config.GetName(),
 		config.GetNamespace(),
-		dexAddress,
-		dexPort,
+		dexIssuer,
 		staticClientsPasswords.Passwords,
 		dexcfg.DefaultCertsDir,
 		config.instance.Spec.StaticClients,
-		connectors,
+		connectors.Len(),
+		connectors.LDAP,
+		connectors.GitHub,
+		connectors.OIDC,
+		connectors.SAML,
+		connectors.GitLab,
+		connectors.Bitbucket,
+		connectors.Microsoft,
+		connectors.Google,
 	}
 
 	configMapBytes, err := util.ParseTemplate(configMapTemplate, replacements)
@@ -152,15 +205,68 @@ func (config *ConfigMap) CreateLocal(connectors []kubicv1beta1.LDAPConnector,
 		glog.V(3).Infof("[kubic] ConfigMap decoding error: %s", err)
 		return fmt.Errorf("unable to decode dex configmap %v", err)
 	}
+
+	config.generateSecret()
 	return nil
 }
 
+// CreateLocalFrom generates a local ConfigMap instance with a configuration
+// previously rendered (for example, when rolling back to a previous configuration).
+// Note well that this instance is not published to the apiserver: users must use
+// `CreateOrUpdate()` for doing that.
+func (config *ConfigMap) CreateLocalFrom(data string) {
+	glog.V(3).Infoln("[kubic] generating local ConfigMap for Dex from a previous configuration")
+	config.generated = &corev1.ConfigMap{
+		ObjectMeta: util.NamaspacedObjToMeta(config),
+		Data: map[string]string{
+			path.Base(config.FileName): data,
+		},
+	}
+	config.generateSecret()
+}
+
+// generateSecret generates the Secret when the configuration is stored in a Secret
+func (config *ConfigMap) generateSecret() {
+	if config.Kind != kindSecret {
+		return
+	}
+	data := map[string][]byte{}
+	for k, v := range config.generated.Data {
+		data[k] = []byte(v)
+	}
+	config.generatedSecret = &corev1.Secret{
+		ObjectMeta: util.NamaspacedObjToMeta(config),
+		Type:       corev1.SecretTypeOpaque,
+		Data:       data,
+	}
+}
+
+// GetCurrentData returns the Dex configuration currently stored in the cluster
+func (config ConfigMap) GetCurrentData() (string, bool) {
+	name := path.Base(config.FileName)
+	switch {
+	case config.currentSecret != nil:
+		data, found := config.currentSecret.Data[name]
+		return string(data), found
+	case config.current != nil:
+		data, found := config.current.Data[name]
+		return data, found
+	}
+	return "", false
+}
+
 // NeedsCreateOrUpdate returns true if the ConfigMap is not in the cluster or it needs to be updated
 // CreateLocal() must have been previously
 func (config ConfigMap) NeedsCreateOrUpdate() bool {
 	if config.generated == nil {
 		panic("ConfigMap has not been generated")
 	}
+	if config.Kind == kindSecret {
+		if config.currentSecret == nil {
+			return true
+		}
+		return !reflect.DeepEqual(config.generatedSecret.Data, config.currentSecret.Data)
+	}
 	if config.current == nil {
 		return true
 	}
@@ -176,19 +282,34 @@ func (config *ConfigMap) CreateOrUpdate() error {
 		panic("ConfigMap has not been generated")
 	}
 
-	// Create the ConfigMap for Dex or update it in case it already exists
-	glog.V(3).Infof("[kubic] creating/updating ConfigMap '%s'", util.NamespacedObjToString(config))
-	err = apiclient.CreateOrUpdateConfigMap(config.reconciler.Clientset, config.generated)
+	// Create the ConfigMap (or Secret) for Dex or update it in case it already exists
+	glog.V(3).Infof("[kubic] creating/updating %s '%s'", config.Kind, util.NamespacedObjToString(config))
+	switch config.Kind {
+	case kindSecret:
+		err = apiclient.CreateOrUpdateSecret(config.reconciler.Clientset, config.generatedSecret)
+	default:
+		err = apiclient.CreateOrUpdateConfigMap(config.reconciler.Clientset, config.generated)
+	}
 	if err != nil {
-		glog.V(3).Infof("[kubic] could not create/update ConfigMap '%s': %s", util.NamespacedObjToString(config), err)
+		glog.V(3).Infof("[kubic] could not create/update %s '%s': %s", config.Kind, util.NamespacedObjToString(config), err)
 		return err
 	}
 
-	glog.V(5).Infof("[kubic] ConfigMap '%s' successfully created: refreshing local copy.", util.NamespacedObjToString(config))
-	config.current, err = config.reconciler.Clientset.Core().ConfigMaps(config.GetNamespace()).Get(config.GetName(), metav1.GetOptions{})
+	glog.V(5).Infof("[kubic] %s '%s' successfully created: refreshing local copy.", config.Kind, util.NamespacedObjToString(config))
+	switch config.Kind {
+	case kindSecret:
+		config.currentSecret, err = config.reconciler.Clientset.Core().Secrets(config.GetNamespace()).Get(config.GetName(), metav1.GetOptions{})
+	default:
+		config.current, err = config.reconciler.Clientset.Core().ConfigMaps(config.GetNamespace()).Get(config.GetName(), metav1.GetOptions{})
+	}
 	if err != nil {
-		glog.V(3).Infof("[kubic] could not create/update ConfigMap '%s': %s", util.NamespacedObjToString(config), err)
-		config.current = nil
+		glog.V(3).Infof("[kubic] could not create/update %s '%s': %s", config.Kind, util.NamespacedObjToString(config), err)
+		config.current, config.currentSecret = nil, nil
+		return err
+	}
+
+	// remove the configuration stored in a different kind of object
+	if err = config.deleteStale(); err != nil {
 		return err
 	}
 
@@ -197,14 +318,14 @@ func (config *ConfigMap) CreateOrUpdate() error {
 
 // GetHashGenerated returns the hash of the generated config map
 func (config ConfigMap) GetHashGenerated() string {
-	data := config.generated.BinaryData
+	data := config.generated.Data
 	name := path.Base(dexcfg.DefaultConfigMapFilename)
 
 	// calculate the sha256 of the data in the Configmap
-	return fmt.Sprintf("%x", sha256.Sum256(data[name]))
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(data[name])))
 }
 
-// Delete removes the current ConfigMap
+// Delete removes the current ConfigMap (or Secret)
 func (config *ConfigMap) Delete() error {
 	if config.current != nil {
 		if err := config.reconciler.Delete(context.TODO(), config.current); err != nil && !apierrors.IsNotFound(err) {
@@ -212,7 +333,34 @@ func (config *ConfigMap) Delete() error {
 		}
 		config.current = nil
 	}
+	if config.currentSecret != nil {
+		if err := config.reconciler.Delete(context.TODO(), config.currentSecret); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		config.currentSecret = nil
+	}
+
+	return config.deleteStale()
+}
+
+// deleteStale removes the configuration previously stored in a different kind of object
+func (config *ConfigMap) deleteStale() error {
+	if config.stale == nil {
+		return nil
+	}
 
+	glog.V(3).Infof("[kubic] removing previous Dex configuration '%s'", util.NamespacedObjToString(config.stale))
+	var err error
+	switch stale := config.stale.(type) {
+	case *corev1.ConfigMap:
+		err = config.reconciler.Delete(context.TODO(), stale)
+	case *corev1.Secret:
+		err = config.reconciler.Delete(context.TODO(), stale)
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	config.stale = nil
 	return nil
 }
 
@@ -221,17 +369,23 @@ func (config *ConfigMap) GetObject() metav1.Object {
 	if config.generated == nil {
 		panic("needs to be generated first")
 	}
+	if config.Kind == kindSecret {
+		return config.generatedSecret
+	}
 	return config.generated
 }
 
 // GetName returns the config name
 func (config ConfigMap) GetName() string {
-	return fmt.Sprintf("%s-cm", dexcfg.DefaultPrefix)
+	if config.Kind == kindSecret {
+		return fmt.Sprintf("%s-config", getPrefix(config.instance))
+	}
+	return fmt.Sprintf("%s-cm", getPrefix(config.instance))
 }
 
-// GetNamespace returns the default namespace
+// GetNamespace returns the namespace where Dex is run
 func (config ConfigMap) GetNamespace() string {
-	return dexDefaultNamespace
+	return getNamespace(config.instance)
 }
 
 // String returns the namespaceObj as a string
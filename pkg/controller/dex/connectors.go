@@ -0,0 +1,1042 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dex
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	certutil "k8s.io/client-go/util/cert"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
+	"github.com/kubic-project/dex-operator/pkg/util"
+	"github.com/kubic-project/dex-operator/pkg/validation"
+)
+
+const (
+	// default key in the Secret where the OAuth client secret is stored
+	defaultClientSecretKey = "clientSecret"
+
+	// default key in the Secret where the LDAP bind password is stored
+	defaultBindPWKey = "bindPW"
+
+	// default key in the Secret where a Google service account is stored
+	defaultServiceAccountKey = "service-account.json"
+
+	// default key in the ConfigMap/Secret where a CA is stored
+	defaultCAKey = "ca.crt"
+
+	// kinds of objects that can be referenced from connectors
+	kindConfigMap = "ConfigMap"
+	kindSecret    = "Secret"
+
+	// reason for connectors removed from a Dex configuration in a rollback
+	connectorRejectedReason = "Rejected"
+)
+
+// LDAPConnector is a LDAP connector with the root CAs resolved. When the bind
+// password is obtained from a Secret, it is provided in the Credentials, so
+// `BindPW` is a reference to an environment variable.
+type LDAPConnector struct {
+	kubicv1beta1.LDAPConnector
+
+	BindPW     string
+	RootCAData string
+}
+
+// GitHubConnector is a GitHub connector. The client secret is provided
+// in the Credentials, so `ClientSecret` is a reference to an environment variable.
+type GitHubConnector struct {
+	kubicv1beta1.GitHubConnector
+
+	ClientSecret string
+}
+
+// OIDCConnector is an OpenID Connect connector. The client secret is provided
+// in the Credentials, so `ClientSecret` is a reference to an environment variable.
+type OIDCConnector struct {
+	kubicv1beta1.OIDCConnector
+
+	ClientSecret string
+}
+
+// SAMLConnector is a SAML connector with the CA resolved
+type SAMLConnector struct {
+	kubicv1beta1.SAMLConnector
+
+	CAData string
+}
+
+// GitLabConnector is a GitLab connector. The client secret is provided
+// in the Credentials, so `ClientSecret` is a reference to an environment variable.
+type GitLabConnector struct {
+	kubicv1beta1.GitLabConnector
+
+	ClientSecret string
+}
+
+// BitbucketCloudConnector is a Bitbucket Cloud connector. The client secret is provided
+// in the Credentials, so `ClientSecret` is a reference to an environment variable.
+type BitbucketCloudConnector struct {
+	kubicv1beta1.BitbucketCloudConnector
+
+	ClientSecret string
+}
+
+// MicrosoftConnector is a Microsoft connector. The client secret is provided
+// in the Credentials, so `ClientSecret` is a reference to an environment variable.
+type MicrosoftConnector struct {
+	kubicv1beta1.MicrosoftConnector
+
+	ClientSecret string
+}
+
+// GoogleConnector is a Google connector. The client secret and the service account
+// are provided in the Credentials, so `ClientSecret` is a reference to an
+// environment variable and `ServiceAccountFilePath` the path of a file.
+type GoogleConnector struct {
+	kubicv1beta1.GoogleConnector
+
+	ClientSecret           string
+	ServiceAccountFilePath string
+}
+
+// Connectors is the list of all the connectors that will be rendered in the Dex configuration
+type Connectors struct {
+	LDAP   []LDAPConnector
+	GitHub []GitHubConnector
+	OIDC   []OIDCConnector
+	SAML   []SAMLConnector
+	GitLab []GitLabConnector
+	// Bitbucket Cloud connectors
+	Bitbucket []BitbucketCloudConnector
+	Microsoft []MicrosoftConnector
+	Google    []GoogleConnector
+}
+
+// Len returns the total number of connectors
+func (c Connectors) Len() int {
+	return len(c.LDAP) + len(c.GitHub) + len(c.OIDC) + len(c.SAML) +
+		len(c.GitLab) + len(c.Bitbucket) + len(c.Microsoft) + len(c.Google)
+}
+
+// getConnectors gets the list of all the connectors selected by a DexConfiguration
+// Some connectors will add their credentials to `creds`.
+func (r *ReconcileDexConfiguration) getConnectors(instance *kubicv1beta1.DexConfiguration, creds *Credentials) (Connectors, error) {
+	var err error
+	connectors := Connectors{}
+
+	opts, err := getConnectorsListOptions(instance)
+	if err != nil {
+		return Connectors{}, err
+	}
+
+	if connectors.LDAP, err = r.getLDAPConnectors(instance, opts, creds); err != nil {
+		return Connectors{}, err
+	}
+	if connectors.GitHub, err = r.getGitHubConnectors(instance, opts, creds); err != nil {
+		return Connectors{}, err
+	}
+	if connectors.OIDC, err = r.getOIDCConnectors(instance, opts, creds); err != nil {
+		return Connectors{}, err
+	}
+	if connectors.SAML, err = r.getSAMLConnectors(instance, opts); err != nil {
+		return Connectors{}, err
+	}
+	if connectors.GitLab, err = r.getGitLabConnectors(instance, opts, creds); err != nil {
+		return Connectors{}, err
+	}
+	if connectors.Bitbucket, err = r.getBitbucketCloudConnectors(instance, opts, creds); err != nil {
+		return Connectors{}, err
+	}
+	if connectors.Microsoft, err = r.getMicrosoftConnectors(instance, opts, creds); err != nil {
+		return Connectors{}, err
+	}
+	if connectors.Google, err = r.getGoogleConnectors(instance, opts, creds); err != nil {
+		return Connectors{}, err
+	}
+
+	// Dex would not start with duplicate IDs: the oldest connector keeps
+	// the ID, and the others are ignored
+	all := connectors.Objects()
+	duplicates := connectors.Remove(func(obj connectorObject) bool {
+		return getOlderConnectorWithID(obj, all) != nil
+	})
+	for _, obj := range duplicates {
+		id, _ := validation.GetConnectorID(obj)
+		message := fmt.Sprintf("ID '%s' is already used by %s", id, connectorName(getOlderConnectorWithID(obj, all)))
+		if err := r.setConnectorInvalid(instance, obj, "DuplicateID", message); err != nil {
+			return Connectors{}, err
+		}
+	}
+
+	return connectors, nil
+}
+
+// getOlderConnectorWithID returns a connector in `all` that has the same ID as `obj` and
+// was created before it (or at the same time, but with a lower name), or nil if there is none
+func getOlderConnectorWithID(obj connectorObject, all []runtime.Object) connectorObject {
+	id, _ := validation.GetConnectorID(obj)
+	if len(id) == 0 {
+		return nil
+	}
+
+	for _, o := range all {
+		other := o.(connectorObject)
+		if otherID, _ := validation.GetConnectorID(other); otherID != id || connectorName(other) == connectorName(obj) {
+			continue
+		}
+		created, otherCreated := obj.GetCreationTimestamp(), other.GetCreationTimestamp()
+		if otherCreated.Before(&created) ||
+			(otherCreated.Equal(&created) && connectorName(other) < connectorName(obj)) {
+			return other
+		}
+	}
+	return nil
+}
+
+// getConnectorsListOptions returns the options for listing the connectors selected by a DexConfiguration
+func getConnectorsListOptions(instance *kubicv1beta1.DexConfiguration) (*client.ListOptions, error) {
+	opts := &client.ListOptions{}
+	if instance.Spec.ConnectorSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(instance.Spec.ConnectorSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid connectorSelector: %s", err)
+		}
+		opts.LabelSelector = selector
+	}
+	return opts, nil
+}
+
+// Objects returns all the connectors as a list of objects
+func (c Connectors) Objects() []runtime.Object {
+	res := []runtime.Object{}
+	for i := range c.LDAP {
+		res = append(res, &c.LDAP[i].LDAPConnector)
+	}
+	for i := range c.GitHub {
+		res = append(res, &c.GitHub[i].GitHubConnector)
+	}
+	for i := range c.OIDC {
+		res = append(res, &c.OIDC[i].OIDCConnector)
+	}
+	for i := range c.SAML {
+		res = append(res, &c.SAML[i].SAMLConnector)
+	}
+	for i := range c.GitLab {
+		res = append(res, &c.GitLab[i].GitLabConnector)
+	}
+	for i := range c.Bitbucket {
+		res = append(res, &c.Bitbucket[i].BitbucketCloudConnector)
+	}
+	for i := range c.Microsoft {
+		res = append(res, &c.Microsoft[i].MicrosoftConnector)
+	}
+	for i := range c.Google {
+		res = append(res, &c.Google[i].GoogleConnector)
+	}
+	return res
+}
+
+// connectorObject is any of the connectors
+type connectorObject interface {
+	runtime.Object
+	metav1.Object
+}
+
+// Keys returns the keys of all the connectors
+func (c Connectors) Keys() []string {
+	res := []string{}
+	for _, obj := range c.Objects() {
+		res = append(res, connectorKey(obj.(connectorObject)))
+	}
+	return res
+}
+
+// Names returns the names of all the connectors, as "Kind/name"
+func (c Connectors) Names() []string {
+	res := []string{}
+	for _, obj := range c.Objects() {
+		res = append(res, connectorName(obj.(connectorObject)))
+	}
+	return res
+}
+
+// Remove removes the connectors for which `f` returns true, returning the connectors removed
+func (c *Connectors) Remove(f func(obj connectorObject) bool) []connectorObject {
+	removed := []connectorObject{}
+
+	ldap := []LDAPConnector{}
+	for i := range c.LDAP {
+		if obj := &c.LDAP[i].LDAPConnector; f(obj) {
+			removed = append(removed, obj)
+		} else {
+			ldap = append(ldap, c.LDAP[i])
+		}
+	}
+	c.LDAP = ldap
+
+	github := []GitHubConnector{}
+	for i := range c.GitHub {
+		if obj := &c.GitHub[i].GitHubConnector; f(obj) {
+			removed = append(removed, obj)
+		} else {
+			github = append(github, c.GitHub[i])
+		}
+	}
+	c.GitHub = github
+
+	oidc := []OIDCConnector{}
+	for i := range c.OIDC {
+		if obj := &c.OIDC[i].OIDCConnector; f(obj) {
+			removed = append(removed, obj)
+		} else {
+			oidc = append(oidc, c.OIDC[i])
+		}
+	}
+	c.OIDC = oidc
+
+	saml := []SAMLConnector{}
+	for i := range c.SAML {
+		if obj := &c.SAML[i].SAMLConnector; f(obj) {
+			removed = append(removed, obj)
+		} else {
+			saml = append(saml, c.SAML[i])
+		}
+	}
+	c.SAML = saml
+
+	gitlab := []GitLabConnector{}
+	for i := range c.GitLab {
+		if obj := &c.GitLab[i].GitLabConnector; f(obj) {
+			removed = append(removed, obj)
+		} else {
+			gitlab = append(gitlab, c.GitLab[i])
+		}
+	}
+	c.GitLab = gitlab
+
+	bitbucket := []BitbucketCloudConnector{}
+	for i := range c.Bitbucket {
+		if obj := &c.Bitbucket[i].BitbucketCloudConnector; f(obj) {
+			removed = append(removed, obj)
+		} else {
+			bitbucket = append(bitbucket, c.Bitbucket[i])
+		}
+	}
+	c.Bitbucket = bitbucket
+
+	microsoft := []MicrosoftConnector{}
+	for i := range c.Microsoft {
+		if obj := &c.Microsoft[i].MicrosoftConnector; f(obj) {
+			removed = append(removed, obj)
+		} else {
+			microsoft = append(microsoft, c.Microsoft[i])
+		}
+	}
+	c.Microsoft = microsoft
+
+	google := []GoogleConnector{}
+	for i := range c.Google {
+		if obj := &c.Google[i].GoogleConnector; f(obj) {
+			removed = append(removed, obj)
+		} else {
+			google = append(google, c.Google[i])
+		}
+	}
+	c.Google = google
+
+	return removed
+}
+
+// connectorKey returns a key that identifies a connector in a specific generation,
+// like "LDAPConnector/my-ldap@2"
+func connectorKey(obj connectorObject) string {
+	return fmt.Sprintf("%s@%d", connectorName(obj), obj.GetGeneration())
+}
+
+// connectorName returns the name of a connector, as "Kind/name"
+func connectorName(obj connectorObject) string {
+	kind := reflect.TypeOf(obj).Elem().Name()
+	return fmt.Sprintf("%s/%s", kind, util.NamespacedObjToString(obj))
+}
+
+// isValidConnector validates a connector, updating its status when it is not valid
+func (r *ReconcileDexConfiguration) isValidConnector(instance *kubicv1beta1.DexConfiguration, obj connectorObject) (bool, error) {
+	if errs := validation.ValidateConnector(obj); len(errs) > 0 {
+		return false, r.setConnectorInvalid(instance, obj, "InvalidSpec", errs.ToAggregate().Error())
+	}
+	return true, nil
+}
+
+// getLDAPConnectors gets the list of LDAP connectors, adding their bind passwords to `creds`
+func (r *ReconcileDexConfiguration) getLDAPConnectors(instance *kubicv1beta1.DexConfiguration, opts *client.ListOptions, creds *Credentials) ([]LDAPConnector, error) {
+	connectors := &kubicv1beta1.LDAPConnectorList{}
+	if err := r.List(context.TODO(), opts, connectors); err != nil {
+		return nil, err
+	}
+
+	res := []LDAPConnector{}
+	for _, c := range connectors.Items {
+		if valid, err := r.isValidConnector(instance, &c); err != nil {
+			return nil, err
+		} else if !valid {
+			continue
+		}
+
+		connector := LDAPConnector{c, c.Spec.BindPW, c.Spec.RootCAData}
+
+		if len(c.Spec.RootCARef.Name) > 0 {
+			rootCA, err := r.getObjectKeyValue(c.Spec.RootCARef, defaultCAKey, getNamespace(instance))
+			if err != nil {
+				message := fmt.Sprintf("could not get the root CA: %s", err)
+				if err := r.setConnectorInvalid(instance, &c, "UnresolvedReference", message); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			connector.RootCAData = rootCA
+		}
+
+		if len(connector.RootCAData) > 0 {
+			if err := validateCABundle(connector.RootCAData); err != nil {
+				message := fmt.Sprintf("invalid root CA: %s", err)
+				if err := r.setConnectorInvalid(instance, &c, "InvalidRootCA", message); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+
+		if len(c.Spec.BindPWSecretRef.Name) > 0 {
+			bindPW, err := r.getSecretValue(c.Spec.BindPWSecretRef, defaultBindPWKey, getNamespace(instance))
+			if err != nil {
+				message := fmt.Sprintf("could not get the bind password: %s", err)
+				if err := r.setConnectorInvalid(instance, &c, "UnresolvedReference", message); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			owner := fmt.Sprintf("ldap-%s", c.GetName())
+			connector.BindPW = creds.AddEnv(owner, "bind-pw", bindPW)
+		} else if len(c.Spec.BindPW) > 0 {
+			glog.Warningf("[kubic] LDAP connector '%s' uses a clear text bindPw: please use a bindPWSecretRef", c.GetName())
+		}
+		res = append(res, connector)
+	}
+
+	return res, nil
+}
+
+// getConnectorStatus returns a pointer to the status of a connector
+func getConnectorStatus(obj connectorObject) *kubicv1beta1.ConnectorStatus {
+	switch c := obj.(type) {
+	case *kubicv1beta1.LDAPConnector:
+		return &c.Status.ConnectorStatus
+	case *kubicv1beta1.GitHubConnector:
+		return &c.Status.ConnectorStatus
+	case *kubicv1beta1.OIDCConnector:
+		return &c.Status.ConnectorStatus
+	case *kubicv1beta1.SAMLConnector:
+		return &c.Status.ConnectorStatus
+	case *kubicv1beta1.GitLabConnector:
+		return &c.Status.ConnectorStatus
+	case *kubicv1beta1.BitbucketCloudConnector:
+		return &c.Status.ConnectorStatus
+	case *kubicv1beta1.MicrosoftConnector:
+		return &c.Status.ConnectorStatus
+	case *kubicv1beta1.GoogleConnector:
+		return &c.Status.ConnectorStatus
+	}
+	panic(fmt.Sprintf("unknown connector type %T", obj))
+}
+
+// setConnectorInvalid updates the status of a connector that has been
+// ignored by `instance` because it is not valid
+func (r *ReconcileDexConfiguration) setConnectorInvalid(instance *kubicv1beta1.DexConfiguration, obj connectorObject, reason, message string) error {
+	glog.V(3).Infof("[kubic] %s ignored in '%s': %s", connectorName(obj), instance.GetName(), message)
+	return r.setConnectorInstanceStatus(obj, kubicv1beta1.ConnectorInstanceStatus{
+		Name:    instance.GetName(),
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+// setConnectorRejected updates the status of a connector that has been removed
+// from the Dex configuration of `instance` because it broke the rollout of Dex
+func (r *ReconcileDexConfiguration) setConnectorRejected(instance *kubicv1beta1.DexConfiguration, obj connectorObject, message string) error {
+	return r.setConnectorInstanceStatus(obj, kubicv1beta1.ConnectorInstanceStatus{
+		Name:    instance.GetName(),
+		Reason:  connectorRejectedReason,
+		Message: message,
+	})
+}
+
+// setConnectorsReady updates the status of the connectors that have been
+// included in the Dex configuration of `instance` with hash `configHash`
+func (r *ReconcileDexConfiguration) setConnectorsReady(instance *kubicv1beta1.DexConfiguration, connectors Connectors, configHash string) error {
+	for _, o := range connectors.Objects() {
+		err := r.setConnectorInstanceStatus(o.(connectorObject), kubicv1beta1.ConnectorInstanceStatus{
+			Name:       instance.GetName(),
+			ConfigHash: configHash,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forgetConnectors removes the state of `instance` from the status of the connectors
+// that are not selected by it anymore (all of them when the instance is being removed)
+func (r *ReconcileDexConfiguration) forgetConnectors(instance *kubicv1beta1.DexConfiguration) error {
+	all, err := validation.ListConnectors(r.Client)
+	if err != nil {
+		return err
+	}
+
+	deleting := instance.GetDeletionTimestamp() != nil
+	for _, o := range all {
+		obj := o.(connectorObject)
+		if !deleting && validation.IsSelectingConnector(instance, obj.GetLabels()) {
+			continue
+		}
+
+		status := getConnectorStatus(obj).DeepCopy()
+		instances := []kubicv1beta1.ConnectorInstanceStatus{}
+		for _, state := range status.Instances {
+			if state.Name != instance.GetName() {
+				instances = append(instances, state)
+			}
+		}
+		if len(instances) == len(status.Instances) {
+			continue
+		}
+		status.Instances = instances
+		summarizeConnectorStatus(obj, status)
+		if err := r.updateConnectorStatus(obj, status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setConnectorInstanceStatus records the state of a connector in one of the DexConfigurations
+// selecting it, updating the conditions of the connector
+func (r *ReconcileDexConfiguration) setConnectorInstanceStatus(obj connectorObject, state kubicv1beta1.ConnectorInstanceStatus) error {
+	current := getConnectorStatus(obj)
+	status := current.DeepCopy()
+
+	found := false
+	for i := range status.Instances {
+		if status.Instances[i].Name == state.Name {
+			if len(state.Message) > 0 && status.Instances[i].Message != state.Message {
+				r.EventRecorder.Event(obj, corev1.EventTypeWarning, getConnectorEventReason(state), state.Message)
+			}
+			status.Instances[i] = state
+			found = true
+			break
+		}
+	}
+	if !found {
+		if len(state.Message) > 0 {
+			r.EventRecorder.Event(obj, corev1.EventTypeWarning, getConnectorEventReason(state), state.Message)
+		}
+		status.Instances = append(status.Instances, state)
+		sort.Slice(status.Instances, func(i, j int) bool {
+			return status.Instances[i].Name < status.Instances[j].Name
+		})
+	}
+
+	summarizeConnectorStatus(obj, status)
+	return r.updateConnectorStatus(obj, status)
+}
+
+// getConnectorEventReason returns the reason for the event emitted when a connector is ignored
+func getConnectorEventReason(state kubicv1beta1.ConnectorInstanceStatus) string {
+	if state.Reason == connectorRejectedReason {
+		return "Rejected"
+	}
+	return "Invalid"
+}
+
+// summarizeConnectorStatus sets the conditions of a connector from its state in all the
+// DexConfigurations selecting it. The result only depends on the `status.Instances`, so
+// several instances updating the same connector agree on its conditions.
+func summarizeConnectorStatus(obj connectorObject, status *kubicv1beta1.ConnectorStatus) {
+	var invalid, rejected *kubicv1beta1.ConnectorInstanceStatus
+	rendered := false
+	for i := range status.Instances {
+		state := &status.Instances[i]
+		switch {
+		case state.Reason == connectorRejectedReason:
+			if rejected == nil {
+				rejected = state
+			}
+		case len(state.Reason) > 0:
+			if invalid == nil {
+				invalid = state
+			}
+		case len(state.ConfigHash) > 0:
+			rendered = true
+		}
+	}
+
+	status.ObservedGeneration = obj.GetGeneration()
+	status.ConnectorID = ""
+	status.Message = ""
+
+	switch {
+	case invalid != nil:
+		status.Conditions = setCondition(status.Conditions, kubicv1beta1.ConnectorReady, corev1.ConditionFalse, invalid.Reason, invalid.Message)
+		status.Message = invalid.Message
+	case rejected != nil:
+		status.Conditions = setCondition(status.Conditions, kubicv1beta1.ConnectorReady, corev1.ConditionFalse, "Rejected", rejected.Message)
+		status.Message = rejected.Message
+	case rendered:
+		status.Conditions = setCondition(status.Conditions, kubicv1beta1.ConnectorReady, corev1.ConditionTrue, "Rendered", "")
+		status.ConnectorID, _ = validation.GetConnectorID(obj)
+	default:
+		status.Conditions = setCondition(status.Conditions, kubicv1beta1.ConnectorReady, corev1.ConditionFalse, "NotSelected", "")
+	}
+
+	if invalid != nil {
+		status.Conditions = setCondition(status.Conditions, kubicv1beta1.ConnectorInvalid, corev1.ConditionTrue, invalid.Reason, invalid.Message)
+	} else {
+		status.Conditions = setCondition(status.Conditions, kubicv1beta1.ConnectorInvalid, corev1.ConditionFalse, "Valid", "")
+	}
+
+	if rejected != nil {
+		status.Conditions = setCondition(status.Conditions, kubicv1beta1.ConnectorRejected, corev1.ConditionTrue, "RolledBack", rejected.Message)
+	} else if getCondition(status.Conditions, kubicv1beta1.ConnectorRejected) != nil {
+		status.Conditions = setCondition(status.Conditions, kubicv1beta1.ConnectorRejected, corev1.ConditionFalse, "Accepted", "")
+	}
+}
+
+// updateConnectorStatus saves the status of a connector, but only when it has changed
+func (r *ReconcileDexConfiguration) updateConnectorStatus(obj connectorObject, status *kubicv1beta1.ConnectorStatus) error {
+	current := getConnectorStatus(obj)
+	if reflect.DeepEqual(*current, *status) {
+		return nil
+	}
+
+	glog.V(5).Infof("[kubic] updating status of %s", connectorName(obj))
+	*current = *status
+	if err := r.Status().Update(context.TODO(), obj); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// getGitHubConnectors gets the list of GitHub connectors, adding their client secrets to `creds`
+func (r *ReconcileDexConfiguration) getGitHubConnectors(instance *kubicv1beta1.DexConfiguration, opts *client.ListOptions, creds *Credentials) ([]GitHubConnector, error) {
+	connectors := &kubicv1beta1.GitHubConnectorList{}
+	if err := r.List(context.TODO(), opts, connectors); err != nil {
+		return nil, err
+	}
+
+	res := []GitHubConnector{}
+	for _, c := range connectors.Items {
+		if valid, err := r.isValidConnector(instance, &c); err != nil {
+			return nil, err
+		} else if !valid {
+			continue
+		}
+		secret, err := r.getSecretValue(c.Spec.ClientSecretRef, defaultClientSecretKey, getNamespace(instance))
+		if err != nil {
+			message := fmt.Sprintf("could not get the client secret: %s", err)
+			if err := r.setConnectorInvalid(instance, &c, "UnresolvedReference", message); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		owner := fmt.Sprintf("github-%s", c.GetName())
+		res = append(res, GitHubConnector{c, creds.AddEnv(owner, "client-secret", secret)})
+	}
+
+	return res, nil
+}
+
+// getOIDCConnectors gets the list of OpenID Connect connectors, adding their client secrets to `creds`
+func (r *ReconcileDexConfiguration) getOIDCConnectors(instance *kubicv1beta1.DexConfiguration, opts *client.ListOptions, creds *Credentials) ([]OIDCConnector, error) {
+	connectors := &kubicv1beta1.OIDCConnectorList{}
+	if err := r.List(context.TODO(), opts, connectors); err != nil {
+		return nil, err
+	}
+
+	res := []OIDCConnector{}
+	for _, c := range connectors.Items {
+		if valid, err := r.isValidConnector(instance, &c); err != nil {
+			return nil, err
+		} else if !valid {
+			continue
+		}
+		secret, err := r.getSecretValue(c.Spec.ClientSecretRef, defaultClientSecretKey, getNamespace(instance))
+		if err != nil {
+			message := fmt.Sprintf("could not get the client secret: %s", err)
+			if err := r.setConnectorInvalid(instance, &c, "UnresolvedReference", message); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		owner := fmt.Sprintf("oidc-%s", c.GetName())
+		res = append(res, OIDCConnector{c, creds.AddEnv(owner, "client-secret", secret)})
+	}
+
+	return res, nil
+}
+
+// getSAMLConnectors gets the list of SAML connectors, with their CAs
+func (r *ReconcileDexConfiguration) getSAMLConnectors(instance *kubicv1beta1.DexConfiguration, opts *client.ListOptions) ([]SAMLConnector, error) {
+	connectors := &kubicv1beta1.SAMLConnectorList{}
+	if err := r.List(context.TODO(), opts, connectors); err != nil {
+		return nil, err
+	}
+
+	res := []SAMLConnector{}
+	for _, c := range connectors.Items {
+		if valid, err := r.isValidConnector(instance, &c); err != nil {
+			return nil, err
+		} else if !valid {
+			continue
+		}
+		ca, err := r.getObjectKeyValue(c.Spec.CARef, defaultCAKey, getNamespace(instance))
+		if err != nil {
+			message := fmt.Sprintf("could not get the CA: %s", err)
+			if err := r.setConnectorInvalid(instance, &c, "UnresolvedReference", message); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		res = append(res, SAMLConnector{c, ca})
+	}
+
+	return res, nil
+}
+
+// getGitLabConnectors gets the list of GitLab connectors, adding their client secrets to `creds`
+func (r *ReconcileDexConfiguration) getGitLabConnectors(instance *kubicv1beta1.DexConfiguration, opts *client.ListOptions, creds *Credentials) ([]GitLabConnector, error) {
+	connectors := &kubicv1beta1.GitLabConnectorList{}
+	if err := r.List(context.TODO(), opts, connectors); err != nil {
+		return nil, err
+	}
+
+	res := []GitLabConnector{}
+	for _, c := range connectors.Items {
+		if valid, err := r.isValidConnector(instance, &c); err != nil {
+			return nil, err
+		} else if !valid {
+			continue
+		}
+		secret, err := r.getSecretValue(c.Spec.ClientSecretRef, defaultClientSecretKey, getNamespace(instance))
+		if err != nil {
+			message := fmt.Sprintf("could not get the client secret: %s", err)
+			if err := r.setConnectorInvalid(instance, &c, "UnresolvedReference", message); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		owner := fmt.Sprintf("gitlab-%s", c.GetName())
+		res = append(res, GitLabConnector{c, creds.AddEnv(owner, "client-secret", secret)})
+	}
+
+	return res, nil
+}
+
+// getBitbucketCloudConnectors gets the list of Bitbucket Cloud connectors, adding their client secrets to `creds`
+func (r *ReconcileDexConfiguration) getBitbucketCloudConnectors(instance *kubicv1beta1.DexConfiguration, opts *client.ListOptions, creds *Credentials) ([]BitbucketCloudConnector, error) {
+	connectors := &kubicv1beta1.BitbucketCloudConnectorList{}
+	if err := r.List(context.TODO(), opts, connectors); err != nil {
+		return nil, err
+	}
+
+	res := []BitbucketCloudConnector{}
+	for _, c := range connectors.Items {
+		if valid, err := r.isValidConnector(instance, &c); err != nil {
+			return nil, err
+		} else if !valid {
+			continue
+		}
+		secret, err := r.getSecretValue(c.Spec.ClientSecretRef, defaultClientSecretKey, getNamespace(instance))
+		if err != nil {
+			message := fmt.Sprintf("could not get the client secret: %s", err)
+			if err := r.setConnectorInvalid(instance, &c, "UnresolvedReference", message); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		owner := fmt.Sprintf("bitbucket-%s", c.GetName())
+		res = append(res, BitbucketCloudConnector{c, creds.AddEnv(owner, "client-secret", secret)})
+	}
+
+	return res, nil
+}
+
+// getMicrosoftConnectors gets the list of Microsoft connectors, adding their client secrets to `creds`
+func (r *ReconcileDexConfiguration) getMicrosoftConnectors(instance *kubicv1beta1.DexConfiguration, opts *client.ListOptions, creds *Credentials) ([]MicrosoftConnector, error) {
+	connectors := &kubicv1beta1.MicrosoftConnectorList{}
+	if err := r.List(context.TODO(), opts, connectors); err != nil {
+		return nil, err
+	}
+
+	res := []MicrosoftConnector{}
+	for _, c := range connectors.Items {
+		if valid, err := r.isValidConnector(instance, &c); err != nil {
+			return nil, err
+		} else if !valid {
+			continue
+		}
+		secret, err := r.getSecretValue(c.Spec.ClientSecretRef, defaultClientSecretKey, getNamespace(instance))
+		if err != nil {
+			message := fmt.Sprintf("could not get the client secret: %s", err)
+			if err := r.setConnectorInvalid(instance, &c, "UnresolvedReference", message); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		owner := fmt.Sprintf("microsoft-%s", c.GetName())
+		res = append(res, MicrosoftConnector{c, creds.AddEnv(owner, "client-secret", secret)})
+	}
+
+	return res, nil
+}
+
+// getGoogleConnectors gets the list of Google connectors, adding their credentials to `creds`
+func (r *ReconcileDexConfiguration) getGoogleConnectors(instance *kubicv1beta1.DexConfiguration, opts *client.ListOptions, creds *Credentials) ([]GoogleConnector, error) {
+	connectors := &kubicv1beta1.GoogleConnectorList{}
+	if err := r.List(context.TODO(), opts, connectors); err != nil {
+		return nil, err
+	}
+
+	res := []GoogleConnector{}
+	for _, c := range connectors.Items {
+		if valid, err := r.isValidConnector(instance, &c); err != nil {
+			return nil, err
+		} else if !valid {
+			continue
+		}
+		owner := fmt.Sprintf("google-%s", c.GetName())
+
+		secret, err := r.getSecretValue(c.Spec.ClientSecretRef, defaultClientSecretKey, getNamespace(instance))
+		if err != nil {
+			message := fmt.Sprintf("could not get the client secret: %s", err)
+			if err := r.setConnectorInvalid(instance, &c, "UnresolvedReference", message); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		serviceAccount := ""
+		if len(c.Spec.ServiceAccountRef.Name) > 0 {
+			serviceAccount, err = r.getSecretValue(c.Spec.ServiceAccountRef, defaultServiceAccountKey, getNamespace(instance))
+			if err != nil {
+				message := fmt.Sprintf("could not get the service account: %s", err)
+				if err := r.setConnectorInvalid(instance, &c, "UnresolvedReference", message); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+
+		// only add the credentials once all the references have been resolved
+		connector := GoogleConnector{c, creds.AddEnv(owner, "client-secret", secret), ""}
+		if len(serviceAccount) > 0 {
+			connector.ServiceAccountFilePath = creds.AddFile(owner, "service-account.json", serviceAccount)
+		}
+		res = append(res, connector)
+	}
+
+	return res, nil
+}
+
+// validateCABundle checks that `data` contains a valid PEM bundle of (one or more) certificates
+func validateCABundle(data string) error {
+	certs, err := certutil.ParseCertsPEM([]byte(data))
+	if err != nil {
+		return err
+	}
+	glog.V(5).Infof("[kubic] %d certificates found in CA bundle", len(certs))
+	return nil
+}
+
+// getSecretValue gets the value of a key in a Secret, looking for the Secret
+// in `defaultNamespace` when the reference has no namespace
+func (r *ReconcileDexConfiguration) getSecretValue(ref kubicv1beta1.SecretKeyReference, defaultKey, defaultNamespace string) (string, error) {
+	return r.getObjectKeyValue(kubicv1beta1.ObjectKeyReference{
+		Kind:      kindSecret,
+		Name:      ref.Name,
+		Namespace: ref.Namespace,
+		Key:       ref.Key,
+	}, defaultKey, defaultNamespace)
+}
+
+// getObjectKeyValue gets the value of a key in a ConfigMap or in a Secret, looking for
+// the object in `defaultNamespace` when the reference has no namespace
+func (r *ReconcileDexConfiguration) getObjectKeyValue(ref kubicv1beta1.ObjectKeyReference, defaultKey, defaultNamespace string) (string, error) {
+	if len(ref.Name) == 0 {
+		return "", fmt.Errorf("no name provided")
+	}
+
+	kind := ref.Kind
+	if len(kind) == 0 {
+		kind = kindConfigMap
+	}
+	namespace := ref.Namespace
+	if len(namespace) == 0 {
+		namespace = defaultNamespace
+	}
+	key := ref.Key
+	if len(key) == 0 {
+		key = defaultKey
+	}
+
+	nname := util.NewNamespacedName(ref.Name, namespace)
+	glog.V(5).Infof("[kubic] getting key '%s' from %s '%s'", key, kind, util.NamespacedNameToString(nname))
+
+	switch kind {
+	case kindSecret:
+		secret, err := r.Clientset.CoreV1().Secrets(namespace).Get(ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		if value, found := secret.Data[key]; found {
+			return string(value), nil
+		}
+	case kindConfigMap:
+		cm, err := r.Clientset.CoreV1().ConfigMaps(namespace).Get(ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		if value, found := cm.Data[key]; found {
+			return value, nil
+		}
+		if value, found := cm.BinaryData[key]; found {
+			return string(value), nil
+		}
+	default:
+		return "", fmt.Errorf("unknown kind '%s': only %s and %s are supported", kind, kindConfigMap, kindSecret)
+	}
+
+	return "", fmt.Errorf("key '%s' not found in %s '%s'", key, kind, util.NamespacedNameToString(nname))
+}
+
+// isSameObject returns true if the reference points to the object of kind `kind` in `meta`.
+// References without a namespace depend on the namespace of the DexConfiguration, so they
+// match objects with the same name in any namespace.
+func isSameObject(ref kubicv1beta1.ObjectKeyReference, kind string, meta metav1.Object) bool {
+	refKind := ref.Kind
+	if len(refKind) == 0 {
+		refKind = kindConfigMap
+	}
+	if refKind != kind || ref.Name != meta.GetName() {
+		return false
+	}
+	return len(ref.Namespace) == 0 || ref.Namespace == meta.GetNamespace()
+}
+
+// getReferencingConnectors returns the connectors that use a ConfigMap or Secret
+func getReferencingConnectors(cli client.Client, kind string, meta metav1.Object) []metav1.Object {
+	res := []metav1.Object{}
+
+	// addIfReferenced adds `c` to the result when any of `refs` points to `meta`
+	addIfReferenced := func(c metav1.Object, refs ...kubicv1beta1.ObjectKeyReference) {
+		for _, ref := range refs {
+			if isSameObject(ref, kind, meta) {
+				res = append(res, c)
+				return
+			}
+		}
+	}
+	secretRef := func(ref kubicv1beta1.SecretKeyReference) kubicv1beta1.ObjectKeyReference {
+		return kubicv1beta1.ObjectKeyReference{Kind: kindSecret, Name: ref.Name, Namespace: ref.Namespace}
+	}
+
+	ldapConnectors := &kubicv1beta1.LDAPConnectorList{}
+	if err := cli.List(context.TODO(), &client.ListOptions{}, ldapConnectors); err == nil {
+		for i := range ldapConnectors.Items {
+			c := &ldapConnectors.Items[i]
+			addIfReferenced(c, secretRef(c.Spec.BindPWSecretRef), c.Spec.RootCARef)
+		}
+	}
+
+	githubConnectors := &kubicv1beta1.GitHubConnectorList{}
+	if err := cli.List(context.TODO(), &client.ListOptions{}, githubConnectors); err == nil {
+		for i := range githubConnectors.Items {
+			c := &githubConnectors.Items[i]
+			addIfReferenced(c, secretRef(c.Spec.ClientSecretRef))
+		}
+	}
+
+	oidcConnectors := &kubicv1beta1.OIDCConnectorList{}
+	if err := cli.List(context.TODO(), &client.ListOptions{}, oidcConnectors); err == nil {
+		for i := range oidcConnectors.Items {
+			c := &oidcConnectors.Items[i]
+			addIfReferenced(c, secretRef(c.Spec.ClientSecretRef))
+		}
+	}
+
+	gitlabConnectors := &kubicv1beta1.GitLabConnectorList{}
+	if err := cli.List(context.TODO(), &client.ListOptions{}, gitlabConnectors); err == nil {
+		for i := range gitlabConnectors.Items {
+			c := &gitlabConnectors.Items[i]
+			addIfReferenced(c, secretRef(c.Spec.ClientSecretRef))
+		}
+	}
+
+	bitbucketConnectors := &kubicv1beta1.BitbucketCloudConnectorList{}
+	if err := cli.List(context.TODO(), &client.ListOptions{}, bitbucketConnectors); err == nil {
+		for i := range bitbucketConnectors.Items {
+			c := &bitbucketConnectors.Items[i]
+			addIfReferenced(c, secretRef(c.Spec.ClientSecretRef))
+		}
+	}
+
+	microsoftConnectors := &kubicv1beta1.MicrosoftConnectorList{}
+	if err := cli.List(context.TODO(), &client.ListOptions{}, microsoftConnectors); err == nil {
+		for i := range microsoftConnectors.Items {
+			c := &microsoftConnectors.Items[i]
+			addIfReferenced(c, secretRef(c.Spec.ClientSecretRef))
+		}
+	}
+
+	googleConnectors := &kubicv1beta1.GoogleConnectorList{}
+	if err := cli.List(context.TODO(), &client.ListOptions{}, googleConnectors); err == nil {
+		for i := range googleConnectors.Items {
+			c := &googleConnectors.Items[i]
+			addIfReferenced(c, secretRef(c.Spec.ClientSecretRef), secretRef(c.Spec.ServiceAccountRef))
+		}
+	}
+
+	samlConnectors := &kubicv1beta1.SAMLConnectorList{}
+	if err := cli.List(context.TODO(), &client.ListOptions{}, samlConnectors); err == nil {
+		for i := range samlConnectors.Items {
+			c := &samlConnectors.Items[i]
+			addIfReferenced(c, c.Spec.CARef)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dex
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
+)
+
+func TestSummarizeConnectorStatus(t *testing.T) {
+	obj := &kubicv1beta1.GitHubConnector{
+		ObjectMeta: metav1.ObjectMeta{Name: "github", Generation: 2},
+		Spec:       kubicv1beta1.GitHubConnectorSpec{ID: "github"},
+	}
+	isReady := func(status *kubicv1beta1.ConnectorStatus) bool {
+		return isConditionTrue(status.Conditions, kubicv1beta1.ConnectorReady)
+	}
+
+	// rendered by two instances, with their own configurations
+	status := &kubicv1beta1.ConnectorStatus{
+		Instances: []kubicv1beta1.ConnectorInstanceStatus{
+			{Name: "tenant-a", ConfigHash: "aaaa"},
+			{Name: "tenant-b", ConfigHash: "bbbb"},
+		},
+	}
+	summarizeConnectorStatus(obj, status)
+	if !isReady(status) || status.ConnectorID != "github" || status.ObservedGeneration != 2 {
+		t.Fatalf("connector rendered in all the instances is not ready: %+v", status)
+	}
+
+	// the result must not depend on the instance updating the status
+	again := status.DeepCopy()
+	summarizeConnectorStatus(obj, again)
+	if again.Conditions[0].Status != status.Conditions[0].Status || again.ConnectorID != status.ConnectorID {
+		t.Fatalf("status changed when summarized again: %+v", again)
+	}
+
+	// rejected by one of the instances
+	status.Instances[1] = kubicv1beta1.ConnectorInstanceStatus{
+		Name: "tenant-b", Reason: connectorRejectedReason, Message: "it broke the rollout",
+	}
+	summarizeConnectorStatus(obj, status)
+	if isReady(status) || !isConditionTrue(status.Conditions, kubicv1beta1.ConnectorRejected) ||
+		status.Message != "it broke the rollout" || len(status.ConnectorID) > 0 {
+		t.Fatalf("connector rejected in an instance is ready: %+v", status)
+	}
+	if isConditionTrue(status.Conditions, kubicv1beta1.ConnectorInvalid) {
+		t.Fatalf("rejected connector is invalid: %+v", status)
+	}
+
+	// the rejecting instance does not select it anymore
+	status.Instances = status.Instances[:1]
+	summarizeConnectorStatus(obj, status)
+	if !isReady(status) {
+		t.Fatalf("connector not ready: %+v", status)
+	}
+	if c := getCondition(status.Conditions, kubicv1beta1.ConnectorRejected); c == nil || c.Status != corev1.ConditionFalse {
+		t.Fatalf("connector still rejected: %+v", status)
+	}
+}
+
+func TestGetOlderConnectorWithID(t *testing.T) {
+	now := metav1.Now()
+	later := metav1.NewTime(now.Add(time.Minute))
+
+	first := &kubicv1beta1.GitHubConnector{
+		ObjectMeta: metav1.ObjectMeta{Name: "first", CreationTimestamp: now},
+		Spec:       kubicv1beta1.GitHubConnectorSpec{ID: "shared"},
+	}
+	second := &kubicv1beta1.OIDCConnector{
+		ObjectMeta: metav1.ObjectMeta{Name: "second", CreationTimestamp: later},
+		Spec:       kubicv1beta1.OIDCConnectorSpec{ID: "shared"},
+	}
+	other := &kubicv1beta1.GitHubConnector{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", CreationTimestamp: later},
+		Spec:       kubicv1beta1.GitHubConnectorSpec{ID: "other"},
+	}
+	all := []runtime.Object{first, second, other}
+
+	if older := getOlderConnectorWithID(first, all); older != nil {
+		t.Errorf("the oldest connector is shadowed by %s", connectorName(older))
+	}
+	if older := getOlderConnectorWithID(second, all); older != first {
+		t.Errorf("the newest connector is not shadowed by the oldest one: %v", older)
+	}
+	if older := getOlderConnectorWithID(other, all); older != nil {
+		t.Errorf("connector with a unique ID is shadowed by %s", connectorName(older))
+	}
+}
@@ -0,0 +1,227 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dex
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/kubernetes/kubernetes/cmd/kubeadm/app/util/apiclient"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
+	dexcfg "github.com/kubic-project/dex-operator/pkg/config"
+	"github.com/kubic-project/dex-operator/pkg/util"
+)
+
+var (
+	// characters that are not valid in environment variables
+	envVarInvalidChars = regexp.MustCompile("[^A-Z0-9_]")
+)
+
+// CredentialEnv is an environment variable in the Dex pods obtained from a key in the Credentials Secret
+type CredentialEnv struct {
+	Name string
+	Key  string
+}
+
+// CredentialFile is a file in the Dex pods obtained from a key in the Credentials Secret
+type CredentialFile struct {
+	Key  string
+	Path string
+}
+
+// Credentials is a Secret (in the same namespace as the Dex Deployment) where the
+// credentials used by the connectors are stored. These credentials are mounted in
+// the Dex pods as environment variables or files, so they do not appear in the ConfigMap.
+type Credentials struct {
+	instance *kubicv1beta1.DexConfiguration
+
+	Env   []CredentialEnv
+	Files []CredentialFile
+
+	data       map[string][]byte
+	current    *corev1.Secret
+	generated  *corev1.Secret
+	reconciler *ReconcileDexConfiguration
+}
+
+// NewCredentialsFor returns a new dex.Credentials
+func NewCredentialsFor(instance *kubicv1beta1.DexConfiguration, reconciler *ReconcileDexConfiguration) (*Credentials, error) {
+	creds := &Credentials{
+		instance:   instance,
+		Env:        []CredentialEnv{},
+		Files:      []CredentialFile{},
+		data:       map[string][]byte{},
+		reconciler: reconciler,
+	}
+
+	if err := creds.GetFrom(instance); err != nil {
+		return nil, err
+	}
+	return creds, nil
+}
+
+// GetFrom obtains the current Credentials Secret
+func (creds *Credentials) GetFrom(instance *kubicv1beta1.DexConfiguration) error {
+	var err error
+
+	creds.current, err = creds.reconciler.Clientset.CoreV1().Secrets(creds.GetNamespace()).Get(creds.GetName(), metav1.GetOptions{})
+	if err != nil {
+		creds.current = nil
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+	} else {
+		glog.V(3).Infof("[kubic] there is an existing Secret with credentials for Dex")
+	}
+
+	return nil
+}
+
+// AddEnv adds a credential that will be provided to Dex as an environment variable.
+// It returns a reference to that variable that can be used in the Dex configuration.
+func (creds *Credentials) AddEnv(owner, name, value string) string {
+	key := fmt.Sprintf("%s-%s", util.SafeID(owner), util.SafeID(name))
+	envName := envVarInvalidChars.ReplaceAllString(strings.ToUpper(key), "_")
+
+	creds.data[key] = []byte(value)
+	creds.Env = append(creds.Env, CredentialEnv{Name: envName, Key: key})
+	return "$" + envName
+}
+
+// AddFile adds a credential that will be provided to Dex as a file.
+// It returns the path of the file in the Dex pods.
+func (creds *Credentials) AddFile(owner, name, value string) string {
+	key := fmt.Sprintf("%s-%s", util.SafeID(owner), util.SafeID(name))
+	filename := path.Join(dexcfg.DefaultCredentialsDir, key)
+
+	creds.data[key] = []byte(value)
+	creds.Files = append(creds.Files, CredentialFile{Key: key, Path: key})
+	return filename
+}
+
+// CreateLocal generates a local Secret instance. Note well that this instance is
+// not published to the apiserver: users must use `CreateOrUpdate()` for doing that.
+func (creds *Credentials) CreateLocal() error {
+	glog.V(3).Infof("[kubic] generating local Secret with %d credentials for Dex", len(creds.data))
+
+	creds.generated = &corev1.Secret{
+		ObjectMeta: util.NamaspacedObjToMeta(creds),
+		Type:       corev1.SecretTypeOpaque,
+		Data:       creds.data,
+	}
+	return nil
+}
+
+// NeedsCreateOrUpdate returns true if the Secret is not in the cluster or it needs to be updated
+// CreateLocal() must have been previously
+func (creds Credentials) NeedsCreateOrUpdate() bool {
+	if creds.generated == nil {
+		panic("Credentials have not been generated")
+	}
+	if creds.current == nil {
+		return true
+	}
+	return !reflect.DeepEqual(creds.generated.Data, creds.current.Data)
+}
+
+// CreateOrUpdate creates the Secret in the apiserver, or updates an existing instance
+func (creds *Credentials) CreateOrUpdate() error {
+	var err error
+
+	if creds.generated == nil {
+		// this would be an error in our program's logic
+		panic("Credentials have not been generated")
+	}
+
+	glog.V(3).Infof("[kubic] creating/updating Secret '%s'", util.NamespacedObjToString(creds))
+	if err = apiclient.CreateOrUpdateSecret(creds.reconciler.Clientset, creds.generated); err != nil {
+		glog.V(3).Infof("[kubic] could not create/update Secret '%s': %s", util.NamespacedObjToString(creds), err)
+		return err
+	}
+
+	creds.current, err = creds.reconciler.Clientset.CoreV1().Secrets(creds.GetNamespace()).Get(creds.GetName(), metav1.GetOptions{})
+	if err != nil {
+		creds.current = nil
+		return err
+	}
+
+	return nil
+}
+
+// GetHashGenerated returns the hash of the generated Secret
+func (creds Credentials) GetHashGenerated() string {
+	if creds.generated == nil {
+		panic("Credentials have not been generated")
+	}
+
+	keys := []string{}
+	for k := range creds.generated.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write(creds.generated.Data[k])
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// Delete removes the current Secret
+func (creds *Credentials) Delete() error {
+	err := creds.reconciler.Clientset.CoreV1().Secrets(creds.GetNamespace()).Delete(creds.GetName(), &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	creds.current = nil
+	return nil
+}
+
+// GetObject returns the metav1.Object generated for the dex.Credentials
+func (creds *Credentials) GetObject() metav1.Object {
+	if creds.generated == nil {
+		panic("needs to be generated first")
+	}
+	return creds.generated
+}
+
+// GetName returns the name of the Secret
+func (creds Credentials) GetName() string {
+	return fmt.Sprintf("%s-credentials", getPrefix(creds.instance))
+}
+
+// GetNamespace returns the namespace where Dex is run
+func (creds Credentials) GetNamespace() string {
+	return getNamespace(creds.instance)
+}
+
+// String returns the namespaceObj as a string
+func (creds Credentials) String() string {
+	return util.NamespacedObjToString(creds)
+}
@@ -37,6 +37,7 @@ spec:
     matchLabels:
       app: {{ .DexName }}
   replicas: {{ .DexDeploymentReplicas }}
+  progressDeadlineSeconds: {{ .DexProgressDeadlineSeconds }}
   template:
     metadata:
       labels:
@@ -49,6 +50,7 @@ spec:
         # of all Dex pods.
         checksum/configmap: {{ .DexConfigMapSha }}
         checksum/secret: {{ .DexCertSha }}
+        checksum/credentials: {{ .DexCredentialsSha }}
     spec:
       serviceAccountName: {{ .DexServiceAccount }}
 
@@ -82,6 +84,19 @@ spec:
         - name: https
           containerPort: 5556
 
+      {{- if .DexCredentialsEnv }}
+
+        # credentials used by the connectors (referenced as $VAR in the config)
+        env:
+        {{- range $Env := .DexCredentialsEnv }}
+        - name: {{ $Env.Name }}
+          valueFrom:
+            secretKeyRef:
+              name: {{ $.DexCredentialsName }}
+              key: {{ $Env.Key }}
+        {{- end }}
+      {{- end }}
+
         # TODO: evaluate if we should use this:
         #
         #securityContext:
@@ -100,7 +115,7 @@ spec:
           successThreshold: 5
           timeoutSeconds: 10
           httpGet:
-            path: /healthz
+            path: {{ .DexIssuerPath }}/healthz
             port: https
             scheme: HTTPS
 
@@ -109,7 +124,7 @@ spec:
           initialDelaySeconds: 30
           timeoutSeconds: 10
           httpGet:
-            path: /healthz
+            path: {{ .DexIssuerPath }}/healthz
             port: https
             scheme: HTTPS
 
@@ -118,11 +133,21 @@ spec:
           mountPath: {{ .DexConfigMapFilename | dirname }}
         - name: tls
           mountPath: {{ .DexCertsDir }}
+      {{- if .DexCredentialsFiles }}
+        - name: credentials
+          mountPath: {{ .DexCredentialsDir }}
+          readOnly: true
+      {{- end }}
 
       volumes:
       - name: config
+      {{- if eq .DexConfigMapKind "Secret" }}
+        secret:
+          secretName: {{ .DexConfigMapName }}
+      {{- else }}
         configMap:
           name: {{ .DexConfigMapName }}
+      {{- end }}
           items:
           - key: {{ .DexConfigMapFilename | basename }}
             path: {{ .DexConfigMapFilename | basename }}
@@ -130,5 +155,17 @@ spec:
       - name: tls
         secret:
           secretName: {{ .DexCertsSecretName }}
+
+    {{- if .DexCredentialsFiles }}
+
+      - name: credentials
+        secret:
+          secretName: {{ .DexCredentialsName }}
+          items:
+        {{- range $File := .DexCredentialsFiles }}
+          - key: {{ $File.Key }}
+            path: {{ $File.Path }}
+        {{- end }}
+    {{- end }}
 `)
 
@@ -26,6 +26,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/kubernetes/kubernetes/cmd/kubeadm/app/util/apiclient"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kuberuntime "k8s.io/apimachinery/pkg/runtime"
@@ -36,6 +37,17 @@ import (
 	"github.com/kubic-project/dex-operator/pkg/util"
 )
 
+const (
+	// the annotation (in the pods template) with the hash of the Dex configuration
+	dexConfigHashAnnotation = "checksum/configmap"
+
+	// the annotation (in the pods template) with the hash of the certificate
+	dexCertHashAnnotation = "checksum/secret"
+
+	// the reason used by the Deployment controller when a rollout does not progress
+	deploymentProgressDeadlineExceededReason = "ProgressDeadlineExceeded"
+)
+
 // Deployment struct
 type Deployment struct {
 	DexCfg *kubicv1beta1.DexConfiguration
@@ -90,12 +102,15 @@ func (deploy *Deployment) GetFrom(instance *kubicv1beta1.DexConfiguration) error
 
 // CreateLocal generates a local Deployment instance. Note well that this instance is
 // not published to the apiserver: users must use `CreateOrUpdate()` for doing that.
-func (deploy *Deployment) CreateLocal(configMap *ConfigMap, cert *Certificate) error {
+func (deploy *Deployment) CreateLocal(configMap *ConfigMap, cert *Certificate, creds *Credentials) error {
 	var err error
 
 	// some checks: deployment cannot access Secrets in different namespaces
 	if cert.GetNamespace() != deploy.GetNamespace() {
-		panic("secret and deployment namespaces must match")
+		return fmt.Errorf("certificate '%s' must be in the '%s' namespace", cert, deploy.GetNamespace())
+	}
+	if creds.GetNamespace() != deploy.GetNamespace() {
+		panic("credentials and deployment namespaces must match")
 	}
 
 	glog.V(3).Infoln("[kubic] generating deployment for Dex")
@@ -112,40 +127,49 @@ func (deploy *Deployment) CreateLocal(configMap *ConfigMap, cert *Certificate) e
 	}
 	glog.V(3).Infof("[kubic] Deployment: cert with HASH=%s", certSha)
 
-	image := deploy.DexCfg.Spec.Image
-	if len(image) == 0 {
-		image = dexDefaultImage
-	}
-
-	replicas := deploy.DexCfg.Spec.Replicas
-	if replicas == 0 {
-		replicas = dexcfg.DefaultDeployNumReplicas
-	}
+	credentialsSha := creds.GetHashGenerated()
+	glog.V(3).Infof("[kubic] Deployment: credentials with HASH=%s", credentialsSha)
 
 	replacements := struct {
-		DexImage              string
-		DexServiceAccount     string
-		DexName               string
-		DexNamespace          string
-		DexDeploymentReplicas int
-		DexCertsSecretName    string
-		DexConfigMapName      string
-		DexConfigMapSha       string
-		DexConfigMapFilename  string
-		DexCertSha            string
-		DexCertsDir           string
+		DexImage                   string
+		DexServiceAccount          string
+		DexName                    string
+		DexNamespace               string
+		DexDeploymentReplicas      int32
+		DexProgressDeadlineSeconds int
+		DexIssuerPath              string
+		DexCertsSecretName         string
+		DexConfigMapName           string
+		DexConfigMapKind           string
+		DexConfigMapSha            string
+		DexConfigMapFilename       string
+		DexCertSha                 string
+		DexCertsDir                string
+		DexCredentialsName         string
+		DexCredentialsSha          string
+		DexCredentialsDir          string
+		DexCredentialsEnv          []CredentialEnv
+		DexCredentialsFiles        []CredentialFile
 	}{
-		image,
-		dexServiceAccountName,
+		deploy.DexCfg.Spec.Image,
+		getDexServiceAccountName(deploy.DexCfg),
 		deploy.GetName(),
 		deploy.GetNamespace(),
-		replicas,
+		getReplicas(deploy.DexCfg),
+		deploy.DexCfg.Spec.ProgressDeadlineSeconds,
+		getIssuerPath(deploy.DexCfg.Status.Issuer),
 		cert.GetName(),
 		configMap.GetName(),
+		configMap.Kind,
 		configMapSha,
 		dexcfg.DefaultConfigMapFilename,
 		certSha,
 		dexcfg.DefaultCertsDir,
+		creds.GetName(),
+		credentialsSha,
+		dexcfg.DefaultCredentialsDir,
+		creds.Env,
+		creds.Files,
 	}
 
 	deploymentBytes, err := util.ParseTemplate(deploymentTemplate, replacements)
@@ -163,11 +187,101 @@ func (deploy *Deployment) CreateLocal(configMap *ConfigMap, cert *Certificate) e
 	return nil
 }
 
+// IsUsingCert returns true if the current Deployment uses the certificate with the given hash
+func (deploy *Deployment) IsUsingCert(hash string) bool {
+	if deploy.current == nil {
+		return false
+	}
+	return deploy.current.Spec.Template.GetAnnotations()[dexCertHashAnnotation] == hash
+}
+
 // IsRunning returns true if the Deployment is not in the cluster or it needs to be updated
 func (deploy *Deployment) IsRunning() bool {
 	return deploy.current != nil
 }
 
+// IsAvailable returns true if the current Deployment has the minimum number of
+// replicas available, as well as a message with the details
+func (deploy *Deployment) IsAvailable() (bool, string) {
+	if deploy.current == nil {
+		return false, "Deployment not found"
+	}
+	for _, c := range deploy.current.Status.Conditions {
+		if c.Type == appsv1.DeploymentAvailable {
+			return c.Status == corev1.ConditionTrue, c.Message
+		}
+	}
+	return false, "Deployment is not available yet"
+}
+
+// GetCurrentConfigHash returns the hash of the configuration used in the current Deployment
+func (deploy *Deployment) GetCurrentConfigHash() string {
+	if deploy.current == nil {
+		return ""
+	}
+	return deploy.current.Spec.Template.Annotations[dexConfigHashAnnotation]
+}
+
+// SetCurrentConfigHash sets the hash of the configuration in the current Deployment,
+// forcing a new rollout of the Dex pods (for example, after rolling back the configuration)
+func (deploy *Deployment) SetCurrentConfigHash(hash string) error {
+	if deploy.current == nil {
+		return fmt.Errorf("there is no Deployment for Dex")
+	}
+
+	updated := deploy.current.DeepCopy()
+	if updated.Spec.Template.Annotations == nil {
+		updated.Spec.Template.Annotations = map[string]string{}
+	}
+	updated.Spec.Template.Annotations[dexConfigHashAnnotation] = hash
+
+	glog.V(5).Infof("[kubic] updating Deployment %s with configuration HASH=%s", deploy, hash)
+	current, err := deploy.reconciler.Clientset.AppsV1().Deployments(updated.GetNamespace()).Update(updated)
+	if err != nil {
+		glog.V(3).Infof("[kubic] ERROR: could not update Deployment '%s': %s", util.NamespacedObjToString(deploy), err)
+		return err
+	}
+	deploy.current = current
+	return nil
+}
+
+// IsRolloutComplete returns true if all the replicas of the current Deployment
+// have been updated and are available
+func (deploy *Deployment) IsRolloutComplete() bool {
+	if deploy.current == nil {
+		return false
+	}
+	current := deploy.current
+	if current.Status.ObservedGeneration < current.GetGeneration() {
+		return false
+	}
+	desired := int32(1)
+	if current.Spec.Replicas != nil {
+		desired = *current.Spec.Replicas
+	}
+	return current.Status.UpdatedReplicas == desired &&
+		current.Status.Replicas == current.Status.UpdatedReplicas &&
+		current.Status.AvailableReplicas == current.Status.UpdatedReplicas
+}
+
+// IsProgressDeadlineExceeded returns true if the rollout of the current Deployment
+// has not made any progress in the `progressDeadlineSeconds`, as well as a message with the details
+func (deploy *Deployment) IsProgressDeadlineExceeded() (bool, string) {
+	if deploy.current == nil {
+		return false, ""
+	}
+	// conditions are not valid until the Deployment controller has seen the latest generation
+	if deploy.current.Status.ObservedGeneration < deploy.current.GetGeneration() {
+		return false, ""
+	}
+	for _, c := range deploy.current.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing {
+			return c.Status == corev1.ConditionFalse && c.Reason == deploymentProgressDeadlineExceededReason, c.Message
+		}
+	}
+	return false, ""
+}
+
 // NeedsCreateOrUpdate returns true if the Deployment is not in the cluster or it needs to be updated
 // CreateLocal() must have been previously
 func (deploy Deployment) NeedsCreateOrUpdate() bool {
@@ -189,7 +303,7 @@ func (deploy *Deployment) CreateOrUpdate() error {
 		panic("Deployment has not been generated")
 	}
 
-	if err := createOrUpdateDexServiceAccount(deploy.reconciler.Clientset); err != nil {
+	if err := createOrUpdateDexServiceAccount(deploy.reconciler.Clientset, deploy.DexCfg); err != nil {
 		glog.V(3).Infof("[kubic] ERROR: could not create/update Service Account for '%s': %s", util.NamespacedObjToString(deploy), err)
 		return err
 	}
@@ -215,18 +329,9 @@ func (deploy *Deployment) CreateOrUpdate() error {
 		return err
 	}
 
-	// Crete the Service and the Network Policy
-	port := dexcfg.DefaultNodePort
-	if deploy.DexCfg.Spec.NodePort != 0 {
-		port = deploy.DexCfg.Spec.NodePort
-	}
-
-	if err := createOrUpdateDexService(deploy.reconciler.Clientset, deploy.GetName(), port); err != nil {
-		glog.V(5).Infof("[kubic] could not create/update Service: %s", err)
-		return err
-	}
-
-	if err := createOrUpdateDexNetworkPolicy(deploy.reconciler.Clientset, deploy.GetName()); err != nil {
+	// Create the Network Policy
+	// (the Service has already been created, as the issuer can depend on it)
+	if err := createOrUpdateDexNetworkPolicy(deploy.reconciler.Clientset, deploy.DexCfg, deploy.GetName()); err != nil {
 		glog.V(5).Infof("[kubic] could not create/update NetworkPolicy: %s", err)
 		return err
 	}
@@ -235,33 +340,38 @@ func (deploy *Deployment) CreateOrUpdate() error {
 }
 
 // Delete removes the current deployment as well as all the other resources created
-// It will ignore IsNotFound errors.
+// It will ignore IsNotFound errors, so it can be called again (ie, when some
+// resource could not be removed, or when the Deployment is already gone).
 func (deploy *Deployment) Delete() error {
-	if deploy.current != nil {
-		if err := apiclient.DeleteDeploymentForeground(deploy.reconciler.Clientset, deploy.GetNamespace(), deploy.GetName()); err != nil {
-			return err
-		}
-		deploy.current = nil
+	err := apiclient.DeleteDeploymentForeground(deploy.reconciler.Clientset, deploy.GetNamespace(), deploy.GetName())
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	deploy.current = nil
 
-		if err := deleteDexRBACRules(deploy.reconciler.Clientset); err != nil {
-			glog.V(3).Infof("[kubic] ERROR: could not delete RBAC rules: %s", err)
-			return err
-		}
+	if err := deleteDexRBACRules(deploy.reconciler.Clientset, deploy.DexCfg); err != nil {
+		glog.V(3).Infof("[kubic] ERROR: could not delete RBAC rules: %s", err)
+		return err
+	}
 
-		if err := deleteDexServiceAccount(deploy.reconciler.Clientset); err != nil {
-			glog.V(3).Infof("[kubic] ERROR: could not delete ServiceAccount: %s", err)
-			return err
-		}
+	if err := deleteDexServiceAccount(deploy.reconciler.Clientset, deploy.DexCfg); err != nil {
+		glog.V(3).Infof("[kubic] ERROR: could not delete ServiceAccount: %s", err)
+		return err
+	}
 
-		if err := deleteDexService(deploy.reconciler.Clientset); err != nil {
-			glog.V(3).Infof("[kubic] ERROR: could not delete Service: %s", err)
-			return err
-		}
+	if err := deleteDexService(deploy.reconciler.Clientset, deploy.DexCfg); err != nil {
+		glog.V(3).Infof("[kubic] ERROR: could not delete Service: %s", err)
+		return err
+	}
 
-		if err := deleteNetworkPolicy(deploy.reconciler.Clientset); err != nil {
-			glog.V(3).Infof("[kubic] ERROR: could not delete NetworkPolicy: %s", err)
-			return err
-		}
+	if err := deleteDexIngress(deploy.reconciler.Clientset, deploy.DexCfg); err != nil {
+		glog.V(3).Infof("[kubic] ERROR: could not delete Ingress: %s", err)
+		return err
+	}
+
+	if err := deleteNetworkPolicy(deploy.reconciler.Clientset, deploy.DexCfg); err != nil {
+		glog.V(3).Infof("[kubic] ERROR: could not delete NetworkPolicy: %s", err)
+		return err
 	}
 
 	return nil
@@ -277,15 +387,23 @@ func (deploy Deployment) GetObject() metav1.Object {
 
 // GetName returns the name of the dex config
 func (deploy Deployment) GetName() string {
-	return fmt.Sprintf("%s-deploy", dexcfg.DefaultPrefix)
+	return fmt.Sprintf("%s-deploy", getPrefix(deploy.DexCfg))
 }
 
-// GetNamespace returns the default dex Namespace
+// GetNamespace returns the namespace where Dex is run
 func (deploy Deployment) GetNamespace() string {
-	return dexDefaultNamespace
+	return getNamespace(deploy.DexCfg)
 }
 
 // String returns the Namespace object as a string
 func (deploy Deployment) String() string {
 	return util.NamespacedObjToString(deploy)
 }
+
+// getReplicas returns the number of replicas for the Dex Deployment
+func getReplicas(instance *kubicv1beta1.DexConfiguration) int32 {
+	if instance.Spec.Replicas != nil {
+		return *instance.Spec.Replicas
+	}
+	return int32(dexcfg.DefaultDeployNumReplicas)
+}
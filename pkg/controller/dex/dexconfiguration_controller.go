@@ -20,6 +20,7 @@ package dex
 import (
 	"context"
 	"fmt"
+	"reflect"
 
 	"github.com/golang/glog"
 	appsv1 "k8s.io/api/apps/v1"
@@ -28,18 +29,24 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
-	dexcfg "github.com/kubic-project/dex-operator/pkg/config"
+	"github.com/kubic-project/dex-operator/pkg/crypto"
+	"github.com/kubic-project/dex-operator/pkg/defaults"
+	"github.com/kubic-project/dex-operator/pkg/util"
+	"github.com/kubic-project/dex-operator/pkg/validation"
 )
 
 const (
@@ -48,9 +55,6 @@ const (
 
 	// Name of the finalizer
 	dexFinalizerName = "dexconfiguration.finalizers.kubic.opensuse.org"
-
-	// Dex main configuration name
-	dexMainConfigName = "dex-configuration"
 )
 
 var (
@@ -69,8 +73,15 @@ func Add(mgr manager.Manager) error {
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	// note well: there is no NewForConfigOrDie() for the dynamic client
+	dyn, err := dynamic.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		panic(err)
+	}
+
 	return &ReconcileDexConfiguration{
 		Clientset:     clientset.NewForConfigOrDie(mgr.GetConfig()),
+		Dynamic:       dyn,
 		Client:        mgr.GetClient(),
 		EventRecorder: mgr.GetRecorder(dexControllerName),
 		scheme:        mgr.GetScheme(),
@@ -91,19 +102,109 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
-	// Watch for changes in LDAPConnectors
-	// They all go to the global DexConfiguration instance
+	// Watch for changes in connectors
+	// They go to the DexConfiguration instances whose connectorSelector matches the labels
+	// of the connector (instances without a connectorSelector use all the connectors)
+	mapConnectorsFn := func(connectors ...metav1.Object) []reconcile.Request {
+		requests := []reconcile.Request{}
+		if len(connectors) == 0 {
+			return requests
+		}
+
+		instances := &kubicv1beta1.DexConfigurationList{}
+		if err := mgr.GetClient().List(context.TODO(), &client.ListOptions{}, instances); err != nil {
+			glog.V(3).Infof("[kubic] ERROR: could not list DexConfigurations: %s", err)
+			return requests
+		}
+		for i := range instances.Items {
+			instance := &instances.Items[i]
+			for _, connector := range connectors {
+				if validation.IsSelectingConnector(instance, connector.GetLabels()) {
+					requests = append(requests, reconcile.Request{
+						NamespacedName: types.NamespacedName{
+							Name:      instance.GetName(),
+							Namespace: instance.GetNamespace(),
+						}})
+					break
+				}
+			}
+		}
+		return requests
+	}
 	mapFn := handler.ToRequestsFunc(
 		func(a handler.MapObject) []reconcile.Request {
-			return []reconcile.Request{
-				{
-					NamespacedName: types.NamespacedName{
-						Name:      dexMainConfigName,
-						Namespace: a.Meta.GetNamespace(),
-					}},
-			}
+			return mapConnectorsFn(a.Meta)
 		})
-	err = c.Watch(&source.Kind{Type: &kubicv1beta1.LDAPConnector{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: mapFn})
+	// the status of the connectors is updated by the instances using them, so only
+	// changes in the spec (or in the labels, for the connectorSelector) are relevant
+	connectorChanged := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.MetaOld.GetGeneration() != e.MetaNew.GetGeneration() ||
+				!reflect.DeepEqual(e.MetaOld.GetLabels(), e.MetaNew.GetLabels())
+		},
+	}
+	err = c.Watch(&source.Kind{Type: &kubicv1beta1.LDAPConnector{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: mapFn}, connectorChanged)
+	if err != nil {
+		return err
+	}
+	err = c.Watch(&source.Kind{Type: &kubicv1beta1.GitHubConnector{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: mapFn}, connectorChanged)
+	if err != nil {
+		return err
+	}
+	err = c.Watch(&source.Kind{Type: &kubicv1beta1.OIDCConnector{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: mapFn}, connectorChanged)
+	if err != nil {
+		return err
+	}
+	err = c.Watch(&source.Kind{Type: &kubicv1beta1.SAMLConnector{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: mapFn}, connectorChanged)
+	if err != nil {
+		return err
+	}
+	err = c.Watch(&source.Kind{Type: &kubicv1beta1.GitLabConnector{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: mapFn}, connectorChanged)
+	if err != nil {
+		return err
+	}
+	err = c.Watch(&source.Kind{Type: &kubicv1beta1.BitbucketCloudConnector{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: mapFn}, connectorChanged)
+	if err != nil {
+		return err
+	}
+	err = c.Watch(&source.Kind{Type: &kubicv1beta1.MicrosoftConnector{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: mapFn}, connectorChanged)
+	if err != nil {
+		return err
+	}
+	err = c.Watch(&source.Kind{Type: &kubicv1beta1.GoogleConnector{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: mapFn}, connectorChanged)
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes in the ConfigMaps and Secrets referenced by the connectors
+	// (ie, CAs or client secrets)
+	referencedMapFn := func(kind string) handler.ToRequestsFunc {
+		return handler.ToRequestsFunc(
+			func(a handler.MapObject) []reconcile.Request {
+				connectors := getReferencingConnectors(mgr.GetClient(), kind, a.Meta)
+				if len(connectors) == 0 {
+					return []reconcile.Request{}
+				}
+				glog.V(5).Infof("[kubic] %s '%s' is used by %d connectors", kind, util.NamespacedObjToString(a.Meta), len(connectors))
+				return mapConnectorsFn(connectors...)
+			})
+	}
+	err = c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: referencedMapFn(kindConfigMap)})
+	if err != nil {
+		return err
+	}
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: referencedMapFn(kindSecret)})
+	if err != nil {
+		return err
+	}
+
+	// Watch the Secrets of the cert-manager Certificates
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(
+			func(a handler.MapObject) []reconcile.Request {
+				return getCertManagerRequests(mgr.GetClient(), a.Meta)
+			}),
+	})
 	if err != nil {
 		return err
 	}
@@ -126,6 +227,7 @@ var _ reconcile.Reconciler = &ReconcileDexConfiguration{}
 type ReconcileDexConfiguration struct {
 	client.Client
 	Clientset clientset.Interface
+	Dynamic   dynamic.Interface
 	record.EventRecorder
 	scheme *runtime.Scheme
 }
@@ -136,12 +238,17 @@ type ReconcileDexConfiguration struct {
 // Automatically generate RBAC rules to allow the Controller to read and write Deployments
 // +kubebuilder:rbac:groups=core,resources=configmaps;secrets;serviceaccounts;services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=events,verbs=create;update;patch
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;create
+// +kubebuilder:rbac:groups=core,resources=nodes;endpoints,verbs=get;list
+// +kubebuilder:rbac:groups=extensions,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create;update;patch;delete;deletecollection
 // +kubebuilder:rbac:groups=certificates.k8s.io,resources=certificatesigningrequests,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=certificates.k8s.io,resources=certificatesigningrequests/approval;certificatesigningrequests/status,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=kubic.opensuse.org,resources=dexconfigurations;ldapconnectors,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=certificates.k8s.io,resources=signers,verbs=approve
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kubic.opensuse.org,resources=dexconfigurations;dexconfigurations/status;ldapconnectors;ldapconnectors/status;githubconnectors;githubconnectors/status;oidcconnectors;oidcconnectors/status;samlconnectors;samlconnectors/status;gitlabconnectors;gitlabconnectors/status;bitbucketcloudconnectors;bitbucketcloudconnectors/status;microsoftconnectors;microsoftconnectors/status;googleconnectors;googleconnectors/status,verbs=get;list;watch;create;update;patch;delete
 func (r *ReconcileDexConfiguration) Reconcile(request reconcile.Request) (reconcile.Result, error) {
 	var err error
 
@@ -163,19 +270,37 @@ func (r *ReconcileDexConfiguration) Reconcile(request reconcile.Request) (reconc
 
 	glog.V(3).Infof("[kubic] ******* processing DexConfiguration instance '%s' *******", instance.GetName())
 
-	if instance.GetName() != dexMainConfigName {
-		msg := fmt.Sprintf("Dex configuration instance '%s' ignored: the only recognized instance is name=%s",
-			instance.GetName(), dexMainConfigName)
-		glog.V(3).Infoln(msg)
-		r.EventRecorder.Event(instance, corev1.EventTypeWarning, "Error", msg)
-		return reconcile.Result{}, nil
+	// the instance as it is stored in the apiserver, so it is only updated when it changes
+	saved := instance.DeepCopy()
+
+	// Instances being removed are not validated, so invalid instances can be finalized
+	deleting := instance.GetDeletionTimestamp() != nil
+	if !deleting {
+		if errs := validation.ValidateDexConfiguration(instance); len(errs) > 0 {
+			msg := fmt.Sprintf("Dex configuration instance '%s' ignored: %s", instance.GetName(), errs.ToAggregate())
+			glog.V(3).Infoln(msg)
+			r.EventRecorder.Event(instance, corev1.EventTypeWarning, "Error", msg)
+			return reconcile.Result{}, nil
+		}
 	}
 
-	// We need some shared secrets
-	// (these secrets must be in the same namespace)
-	staticClientsPasswords, err := NewStaticClientsPasswords(dexcfg.DefaultPrefix, instance.GetNamespace())
-	if err != nil {
-		return reconcile.Result{}, err
+	// Make the defaults visible in the instance (it will be saved at the end)
+	if defaults.SetDexConfigurationDefaults(instance) {
+		glog.V(3).Infof("[kubic] default values set in DexConfiguration instance '%s'", instance.GetName())
+	}
+
+	// Check this instance does not conflict with other (older) instances
+	if !deleting {
+		instances := &kubicv1beta1.DexConfigurationList{}
+		if err = r.List(ctx, &client.ListOptions{}, instances); err != nil {
+			return reconcile.Result{}, err
+		}
+		if errs := validation.ValidateDexConfigurationConflicts(instance, instances.Items); len(errs) > 0 {
+			msg := fmt.Sprintf("Dex configuration instance '%s' ignored: %s", instance.GetName(), errs.ToAggregate())
+			glog.V(3).Infoln(msg)
+			r.EventRecorder.Event(instance, corev1.EventTypeWarning, "Error", msg)
+			return reconcile.Result{}, nil
+		}
 	}
 
 	staticClients := append(instance.Spec.StaticClients, dexDefaultStaticClient)
@@ -183,6 +308,21 @@ func (r *ReconcileDexConfiguration) Reconcile(request reconcile.Request) (reconc
 	for _, sc := range staticClients {
 		staticClientsNames = append(staticClientsNames, sc.Name)
 	}
+
+	// Move Dex to a new namespace when the namespace has changed
+	if !deleting {
+		if err = r.reconcileNamespace(instance, staticClientsNames); err != nil {
+			glog.V(3).Infof("[kubic] ERROR: when moving Dex to namespace '%s': %s", getNamespace(instance), err)
+			return reconcile.Result{}, err
+		}
+	}
+
+	// We need some shared secrets
+	// (these secrets must be in the same namespace)
+	staticClientsPasswords, err := NewStaticClientsPasswords(getPrefix(instance), getNamespace(instance))
+	if err != nil {
+		return reconcile.Result{}, err
+	}
 	if err = staticClientsPasswords.GetOrRandomFromSecrets(r.Clientset, staticClientsNames); err != nil {
 		return reconcile.Result{}, err
 	}
@@ -199,29 +339,52 @@ func (r *ReconcileDexConfiguration) Reconcile(request reconcile.Request) (reconc
 	}
 
 	// check if the object is being removed and, in this case, delete all related objects
-	finalizing, err := r.finalizerCheck(instance)
-	if err != nil {
+	finalizing := r.finalizerCheck(instance)
+
+	// save the defaults and the finalizer before creating anything
+	if err = r.updateInstance(ctx, saved, instance); err != nil {
 		return reconcile.Result{}, err
 	}
 
 	if finalizing {
 		err = r.reconcileRemoval(instance, deployment, configMap, staticClientsPasswords)
+		// the instance is gone, so it must not appear in the status of the connectors
+		if ferr := r.forgetConnectors(instance); ferr != nil && err == nil {
+			err = ferr
+		}
 		r.finalizerDone(instance)
 	} else {
 		rr, err = r.reconcileInstance(instance, deployment, configMap, staticClientsPasswords)
 	}
 
+	// check the rollout, rolling back to a good configuration if it has failed
+	requeueAfter := r.updateInstanceRollout(instance, deployment)
+	if !finalizing && err == nil {
+		err = r.reconcileRevisions(instance, configMap, deployment)
+	}
+
 	if err != nil {
 		r.EventRecorder.Event(instance, corev1.EventTypeWarning, "Error", fmt.Sprintf("%s", err))
 	}
+	if requeueAfter > 0 && rr.RequeueAfter == 0 {
+		rr.RequeueAfter = requeueAfter
+	}
+	// check the certificate again before it expires
+	if certRequeueAfter := getCertificateRequeueAfter(instance); !finalizing && certRequeueAfter > 0 &&
+		(rr.RequeueAfter == 0 || certRequeueAfter < rr.RequeueAfter) {
+		rr.RequeueAfter = certRequeueAfter
+	}
+	updateInstanceConditions(instance, deployment, err)
+
+	// update the instance when it has been modified (despite any previous error)
+	if err := r.updateInstance(ctx, saved, instance); err != nil {
+		return reconcile.Result{}, err
+	}
 
-	// update the instance (despite any previous error)
 	glog.V(3).Infof("[kubic] updating Status in DexConfiguration instance '%s'", instance.GetName())
-	if err := r.Update(ctx, instance); err != nil {
-		glog.V(3).Infof("[kubic] ERROR: when updating DexConfiguration instance '%s': %s", instance.GetName(), err)
-		if !apierrors.IsNotFound(err) {
-			return reconcile.Result{}, err
-		}
+	if err := r.updateInstanceStatus(instance); err != nil {
+		glog.V(3).Infof("[kubic] ERROR: %s", err)
+		return reconcile.Result{}, err
 	}
 	glog.V(3).Infof("[kubic] .status successfully updated for DexConfiguration '%s'", instance.GetName())
 
@@ -234,39 +397,79 @@ func (r *ReconcileDexConfiguration) reconcileInstance(instance *kubicv1beta1.Dex
 
 	var err error
 
-	connectors, err := r.getLDAPConnectors()
+	credentials, err := NewCredentialsFor(instance, r)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
+	connectors, err := r.getConnectors(instance, credentials)
+	if err != nil {
+		setInstanceConditionFromError(instance, kubicv1beta1.DexConfigRendered, "InvalidConnectors", err)
+		return reconcile.Result{}, err
+	}
+	if err = r.rejectConnectors(instance, &connectors); err != nil {
+		return reconcile.Result{}, err
+	}
+	if err = r.forgetConnectors(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+	instance.Status.Connectors = connectors.Names()
+
 	// If no connectors are available, Dex should not be running at all
-	if len(connectors) == 0 && deployment.IsRunning() {
-		glog.V(3).Infof("[kubic] no LDAP connectors available, and Dex was running: removing Deployment '%s'", deployment.GetName())
+	if connectors.Len() == 0 && deployment.IsRunning() {
+		glog.V(3).Infof("[kubic] no connectors available, and Dex was running: removing Deployment '%s'", deployment.GetName())
 		if err = r.reconcileRemoval(instance, deployment, configMap, staticClientPasswords); err != nil {
 			return reconcile.Result{}, err
 		}
 		return reconcile.Result{}, nil
 	}
 
-	instance.Status.NumConnectors = len(connectors)
+	instance.Status.NumConnectors = connectors.Len()
+
+	if err = createDexNamespace(r.Clientset, instance); err != nil {
+		glog.V(3).Infof("[kubic] ERROR: when creating the namespace for Dex: %s", err)
+		return reconcile.Result{}, err
+	}
+
+	// Expose Dex and get the issuer URL
+	if err = r.reconcileExposure(instance, deployment); err != nil {
+		return reconcile.Result{}, err
+	}
+	dexIssuer, err := r.getDexIssuer(instance)
+	if err == errNoLoadBalancerAddress {
+		glog.V(3).Infof("[kubic] %s: will try again in %s", err, dexLoadBalancerRequeuePeriod)
+		setInstanceCondition(instance, kubicv1beta1.DexConfigRendered, corev1.ConditionFalse, "WaitingForAddress", err.Error())
+		return reconcile.Result{RequeueAfter: dexLoadBalancerRequeuePeriod}, nil
+	} else if err != nil {
+		setInstanceConditionFromError(instance, kubicv1beta1.DexConfigRendered, "NoIssuer", err)
+		return reconcile.Result{}, err
+	}
+	instance.Status.Issuer = dexIssuer
 
-	if err = configMap.CreateLocal(connectors, staticClientPasswords); err != nil {
+	if err = configMap.CreateLocal(dexIssuer, connectors, staticClientPasswords); err != nil {
 		glog.V(3).Infof("[kubic] ERROR: when creating Dex ConfigMap: %s", err)
+		setInstanceConditionFromError(instance, kubicv1beta1.DexConfigRendered, "RenderError", err)
 		return reconcile.Result{}, err
 	}
 	if err = r.setOwner(instance, configMap); err != nil {
 		return reconcile.Result{}, err
 	}
 
-	if !configMap.NeedsCreateOrUpdate() {
-		glog.V(3).Infoln("[kubic] Dex ConfigMap is still valid: nothing to do.")
+	// do not try again a configuration that has been rolled back
+	if hash := configMap.GetHashGenerated(); isFailedConfig(instance, hash) {
+		message := fmt.Sprintf("configuration %s has been rolled back in the past: ignored", hash)
+		glog.V(3).Infof("[kubic] %s", message)
+		setInstanceCondition(instance, kubicv1beta1.DexConfigRendered, corev1.ConditionFalse, "RolledBack", message)
 		return reconcile.Result{}, nil
 	}
 
-	glog.V(3).Infoln("[kubic] Dex ConfigMap is missing or has changed: will be created/updated...")
-	r.EventRecorder.Event(instance, corev1.EventTypeNormal,
-		"Checking", fmt.Sprintf("ConfigMap '%s' for '%s' has changed",
-			configMap.GetName(), instance.GetName()))
+	if err = credentials.CreateLocal(); err != nil {
+		glog.V(3).Infof("[kubic] ERROR: when creating Dex credentials: %s", err)
+		return reconcile.Result{}, err
+	}
+	if err = r.setOwner(instance, credentials); err != nil {
+		return reconcile.Result{}, err
+	}
 
 	// Get a valid certificate, signed by the CA, for Dex
 	certificate, err := NewCertificate(instance, r)
@@ -274,19 +477,61 @@ func (r *ReconcileDexConfiguration) reconcileInstance(instance *kubicv1beta1.Dex
 		glog.V(3).Infof("[kubic] ERROR: when creating Dex certificate: %s", err)
 		return reconcile.Result{}, err
 	}
-	if err := certificate.CreateOrUpdate(deployment); err != nil {
+	err = certificate.CreateOrUpdate(deployment)
+	if err == crypto.ErrCSRPending {
+		glog.V(3).Infof("[kubic] %s: will try again in %s", err, dexCSRRequeuePeriod)
+		setInstanceCondition(instance, kubicv1beta1.DexCertificateReady, corev1.ConditionFalse, "WaitingForSigner", err.Error())
+		return reconcile.Result{RequeueAfter: dexCSRRequeuePeriod}, nil
+	} else if err == crypto.ErrSignerRequired {
+		msg := fmt.Sprintf("%s: set a certificateSignerName (or use cert-manager)", err)
+		glog.V(3).Infof("[kubic] ERROR: %s", msg)
+		r.EventRecorder.Event(instance, corev1.EventTypeWarning, "SignerRequired", msg)
+		setInstanceCondition(instance, kubicv1beta1.DexCertificateReady, corev1.ConditionFalse, "SignerRequired", msg)
+		return reconcile.Result{}, nil
+	} else if err == errCertManagerPending {
+		glog.V(3).Infof("[kubic] %s: will try again in %s", err, dexCertManagerRequeuePeriod)
+		setInstanceCondition(instance, kubicv1beta1.DexCertificateReady, corev1.ConditionFalse, "WaitingForCertManager", err.Error())
+		return reconcile.Result{RequeueAfter: dexCertManagerRequeuePeriod}, nil
+	} else if err == nil {
+		err = certificate.VerifyIssuer(dexIssuer)
+	}
+	if reason, message := certificate.GetWarning(); err == nil && len(reason) > 0 {
+		// keep the reason, so the warning is not emitted again
+		setInstanceCondition(instance, kubicv1beta1.DexCertificateReady, corev1.ConditionTrue, reason, message)
+	} else {
+		setInstanceConditionFromError(instance, kubicv1beta1.DexCertificateReady, "Certificate", err)
+	}
+	if err != nil {
 		glog.V(3).Infof("[kubic] ERROR: when creating/updating Dex certificate: %s", err)
 		return reconcile.Result{}, err
 	}
 	if certificate.WasGenerated() {
 		instance.Status.GeneratedCertificate = certificate.AsSecretReference()
 	}
+	if notAfter, err := certificate.GetExpiration(); err == nil {
+		instance.Status.CertificateNotAfter = &metav1.Time{Time: notAfter}
+	}
 	if err = r.setOwner(instance, certificate); err != nil {
 		return reconcile.Result{}, err
 	}
 
+	// note well: a new certificate (ie, renewed by cert-manager) must be rolled out even if
+	// the configuration has not changed
+	certChanged := certificate.WasGenerated() ||
+		(deployment.IsRunning() && !deployment.IsUsingCert(certificate.GetHashRequested()))
+	if !configMap.NeedsCreateOrUpdate() && !credentials.NeedsCreateOrUpdate() && !certChanged {
+		glog.V(3).Infoln("[kubic] Dex ConfigMap and credentials are still valid: nothing to do.")
+		setInstanceCondition(instance, kubicv1beta1.DexConfigRendered, corev1.ConditionTrue, "UpToDate", "")
+		return reconcile.Result{}, r.setConnectorsReady(instance, connectors, configMap.GetHashGenerated())
+	}
+
+	glog.V(3).Infof("[kubic] Dex %s is missing or has changed: will be created/updated...", configMap.Kind)
+	r.EventRecorder.Event(instance, corev1.EventTypeNormal,
+		"Checking", fmt.Sprintf("%s '%s' for '%s' has changed",
+			configMap.Kind, configMap.GetName(), instance.GetName()))
+
 	// Generate the deployment and create/update it
-	if err = deployment.CreateLocal(configMap, certificate); err != nil {
+	if err = deployment.CreateLocal(configMap, certificate, credentials); err != nil {
 		glog.V(3).Infof("[kubic] ERROR: when creating Dex Deployment: %s", err)
 		return reconcile.Result{}, err
 	}
@@ -321,20 +566,32 @@ func (r *ReconcileDexConfiguration) reconcileInstance(instance *kubicv1beta1.Dex
 		"Deploying", fmt.Sprintf("Created %d Secrets for shared passwords for '%s'",
 			len(staticClientPasswords.Passwords), instance.GetName()))
 
-	if err = configMap.CreateOrUpdate(); err != nil {
+	err = configMap.CreateOrUpdate()
+	setInstanceConditionFromError(instance, kubicv1beta1.DexConfigRendered, "Stored", err)
+	if err != nil {
 		return reconcile.Result{}, err
 	}
 	instance.Status.Config = configMap.String()
+	instance.Status.ConfigKind = configMap.Kind
+	if err = r.setConnectorsReady(instance, connectors, configMap.GetHashGenerated()); err != nil {
+		return reconcile.Result{}, err
+	}
 	r.EventRecorder.Event(instance, corev1.EventTypeNormal,
-		"Deploying", fmt.Sprintf("Configmap '%s' created for '%s'",
-			configMap.GetName(), instance.GetName()))
+		"Deploying", fmt.Sprintf("%s '%s' created for '%s'",
+			configMap.Kind, configMap.GetName(), instance.GetName()))
+
+	if err = credentials.CreateOrUpdate(); err != nil {
+		return reconcile.Result{}, err
+	}
 
 	if err = deployment.CreateOrUpdate(); err != nil {
 		return reconcile.Result{}, err
 	}
 	instance.Status.Deployment = deployment.String()
+	instance.Status.Namespace = deployment.GetNamespace()
+	startInstanceRollout(instance, configMap.GetHashGenerated(), connectors.Keys())
 	r.EventRecorder.Event(instance, corev1.EventTypeNormal,
-		"Deploying", fmt.Sprintf("Deployment '%s' created for '%s'",
+		"Deploying", fmt.Sprintf("Deployment '%s' created for '%s': waiting for the rollout",
 			deployment.GetName(), instance.GetName()))
 
 	return reconcile.Result{}, nil
@@ -353,27 +610,47 @@ func (r *ReconcileDexConfiguration) reconcileRemoval(instance *kubicv1beta1.DexC
 	r.EventRecorder.Event(instance, corev1.EventTypeNormal,
 		"Removing", fmt.Sprintf("Removing all the dependencies for '%s'...", instance.GetName()))
 
-	if len(instance.Status.Deployment) > 0 {
-		if err = deployment.Delete(); err != nil {
-			// ignore the deletion error
-			glog.V(5).Infof("[kubic] ERROR: could not remove Deployment '%s' for '%s': %s", deployment, instance.GetName(), err)
-		} else {
-			r.EventRecorder.Event(instance, corev1.EventTypeNormal,
-				"Removing", fmt.Sprintf("Deployment '%s' removed", deployment.GetName()))
-		}
-		instance.Status.Deployment = ""
+	// the Service, NetworkPolicy... can exist even if the Deployment was never created
+	if err = deployment.Delete(); err != nil {
+		// ignore the deletion error
+		glog.V(5).Infof("[kubic] ERROR: could not remove Deployment '%s' for '%s': %s", deployment, instance.GetName(), err)
+	} else if len(instance.Status.Deployment) > 0 {
+		r.EventRecorder.Event(instance, corev1.EventTypeNormal,
+			"Removing", fmt.Sprintf("Deployment '%s' removed", deployment.GetName()))
 	}
+	instance.Status.Deployment = ""
 
 	if len(instance.Status.Config) > 0 {
 		if err = configMap.Delete(); err != nil {
 			// ignore the deletion error
-			glog.V(5).Infof("[kubic] ERROR: could not remove configmap '%s' for %s: %s",
-				configMap.GetName(), instance.GetName(), err)
+			glog.V(5).Infof("[kubic] ERROR: could not remove %s '%s' for %s: %s",
+				configMap.Kind, configMap.GetName(), instance.GetName(), err)
 		} else {
 			r.EventRecorder.Event(instance, corev1.EventTypeNormal,
-				"Removing", fmt.Sprintf("Configmap '%s' removed", configMap.GetName()))
+				"Removing", fmt.Sprintf("%s '%s' removed", configMap.Kind, configMap.GetName()))
 		}
 		instance.Status.Config = ""
+		instance.Status.ConfigKind = ""
+	}
+
+	// remove the configurations kept for rollbacks
+	if revisions, err := NewConfigRevisionsFor(instance, r); err == nil {
+		if err := revisions.Delete(); err != nil {
+			// ignore the deletion error
+			glog.V(5).Infof("[kubic] ERROR: could not remove revisions '%s' for '%s': %s",
+				revisions.GetName(), instance.GetName(), err)
+		}
+	}
+	instance.Status.Revisions = nil
+	instance.Status.FailedConfigHashes = nil
+
+	// remove the credentials used by the connectors
+	if credentials, err := NewCredentialsFor(instance, r); err == nil {
+		if err := credentials.Delete(); err != nil {
+			// ignore the deletion error
+			glog.V(5).Infof("[kubic] ERROR: could not remove credentials '%s' for '%s': %s",
+				credentials.GetName(), instance.GetName(), err)
+		}
 	}
 
 	// remove the staticClientsPasswords and the certificate
@@ -397,7 +674,19 @@ func (r *ReconcileDexConfiguration) reconcileRemoval(instance *kubicv1beta1.DexC
 		instance.Status.GeneratedCertificate = corev1.SecretReference{}
 	}
 
+	// ... or the cert-manager Certificate
+	if err := r.deleteCertManagerCertificate(instance); err != nil {
+		// ignore the deletion error
+		glog.V(5).Infof("[kubic] ERROR: removing cert-manager Certificate '%s' for '%s': %s",
+			instance.Status.CertManagerCertificate, instance.GetName(), err)
+	}
+
 	instance.Status.NumConnectors = 0
+	instance.Status.Connectors = nil
+	instance.Status.Namespace = ""
+	instance.Status.Issuer = ""
+	instance.Status.DiscoveredAddress = ""
+	instance.Status.CertificateNotAfter = nil
 
 	return nil
 }
@@ -414,23 +703,31 @@ func (r *ReconcileDexConfiguration) setOwner(instance metav1.Object, obj ObjectV
 	return nil
 }
 
-// getLDAPCOnnectors gets the list of LDAP connectors
-func (r *ReconcileDexConfiguration) getLDAPConnectors() ([]kubicv1beta1.LDAPConnector, error) {
-	// Get the list of LDAP connectors
-	connectors := &kubicv1beta1.LDAPConnectorList{}
-	if err := r.List(context.TODO(), &client.ListOptions{}, connectors); err != nil {
-		return nil, err
+// updateInstance saves the metadata and the spec of a DexConfiguration, but only when they
+// are different in `saved` (the instance stored in the apiserver). `saved` is updated after
+// saving the instance.
+// note well: the status is not saved with Update(), and it is overwritten with the current status
+func (r *ReconcileDexConfiguration) updateInstance(ctx context.Context, saved, instance *kubicv1beta1.DexConfiguration) error {
+	if reflect.DeepEqual(saved.Spec, instance.Spec) && reflect.DeepEqual(saved.Finalizers, instance.Finalizers) {
+		return nil
 	}
 
-	// for _, c := range connectors.Items {
-	// }
-
-	return connectors.Items, nil
+	status := instance.Status.DeepCopy()
+	glog.V(3).Infof("[kubic] updating DexConfiguration instance '%s'", instance.GetName())
+	if err := r.Update(ctx, instance); err != nil {
+		glog.V(3).Infof("[kubic] ERROR: when updating DexConfiguration instance '%s': %s", instance.GetName(), err)
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	instance.Status = *status
+	instance.DeepCopyInto(saved)
+	return nil
 }
 
 // finalizerCheck checks if the object is being finalized and, in that case,
 // remove all the related objects
-func (r *ReconcileDexConfiguration) finalizerCheck(instance *kubicv1beta1.DexConfiguration) (bool, error) {
+func (r *ReconcileDexConfiguration) finalizerCheck(instance *kubicv1beta1.DexConfiguration) bool {
 	// Helper functions to check and remove string from a slice of strings.
 	containsString := func(slice []string, s string) bool {
 		for _, item := range slice {
@@ -444,20 +741,17 @@ func (r *ReconcileDexConfiguration) finalizerCheck(instance *kubicv1beta1.DexCon
 	finalizing := false
 	if instance.ObjectMeta.DeletionTimestamp.IsZero() {
 		// The object is not being deleted, so if it does not have our finalizer,
-		// then lets add the finalizer and update the object.
+		// then lets add the finalizer (the object will be updated by the caller).
 		if !containsString(instance.ObjectMeta.Finalizers, dexFinalizerName) {
 			glog.V(3).Infof("[kubic] '%s' does not have finalizer '%s' registered: adding it", instance.GetName(), dexFinalizerName)
 			instance.ObjectMeta.Finalizers = append(instance.ObjectMeta.Finalizers, dexFinalizerName)
-			if err := r.Update(context.Background(), instance); err != nil {
-				return false, err
-			}
 		}
 	} else {
 		glog.V(3).Infof("[kubic] '%s' is being deleted", instance.GetName())
 		finalizing = true
 	}
 
-	return finalizing, nil
+	return finalizing
 }
 
 // finalizerDone marks the instance as "we are done with it, you can remove it now"
@@ -0,0 +1,152 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dex
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
+	dexnet "github.com/kubic-project/dex-operator/pkg/net"
+)
+
+const (
+	// the port where the Dex Service listens
+	dexServicePort = 5556
+
+	// time between checks of the address assigned to a LoadBalancer
+	dexLoadBalancerRequeuePeriod = 10 * time.Second
+)
+
+var (
+	// errNoLoadBalancerAddress is returned when the LoadBalancer has not got an address yet
+	errNoLoadBalancerAddress = errors.New("waiting for an address for the LoadBalancer")
+)
+
+// getExposeMode returns the way Dex is exposed for a DexConfiguration
+func getExposeMode(instance *kubicv1beta1.DexConfiguration) kubicv1beta1.DexExposeMode {
+	if len(instance.Spec.Expose.Mode) == 0 {
+		return kubicv1beta1.DexExposeNodePort
+	}
+	return instance.Spec.Expose.Mode
+}
+
+// getDexIssuer returns the issuer URL for a DexConfiguration, that depends on the way Dex is exposed:
+// * NodePort: the first name (or the discovered public address) and the NodePort
+// * LoadBalancer: the first name (or the IP of the LoadBalancer) and the Service port
+// * Ingress: the host in the Ingress
+// The `issuer` in the spec overrides all these values.
+func (r *ReconcileDexConfiguration) getDexIssuer(instance *kubicv1beta1.DexConfiguration) (string, error) {
+	var err error
+	var address string
+	var port int
+
+	instance.Status.DiscoveredAddress = ""
+	if len(instance.Spec.Issuer) > 0 {
+		return instance.Spec.Issuer, nil
+	}
+
+	switch getExposeMode(instance) {
+	case kubicv1beta1.DexExposeIngress:
+		return fmt.Sprintf("https://%s", instance.Spec.Expose.Ingress.Host), nil
+
+	case kubicv1beta1.DexExposeLoadBalancer:
+		port = dexServicePort
+		switch {
+		case len(instance.Spec.Names) > 0:
+			address = instance.Spec.Names[0]
+		case len(instance.Spec.Expose.LoadBalancerIP) > 0:
+			address = instance.Spec.Expose.LoadBalancerIP
+		default:
+			if address, err = r.getLoadBalancerAddress(instance); err != nil {
+				return "", err
+			}
+		}
+
+	default:
+		port = instance.Spec.NodePort
+		if len(instance.Spec.Names) > 0 {
+			address = instance.Spec.Names[0]
+		} else if address, err = dexnet.GetPublicAPIAddress(r.Clientset); err != nil {
+			return "", err
+		} else {
+			glog.V(3).Infof("[kubic] public address discovered: %s", address)
+			instance.Status.DiscoveredAddress = address
+		}
+	}
+
+	return fmt.Sprintf("https://%s", net.JoinHostPort(address, strconv.Itoa(port))), nil
+}
+
+// getIssuerPath returns the path in the issuer URL (without the trailing slash),
+// where Dex serves all its endpoints
+func getIssuerPath(issuer string) string {
+	u, err := url.Parse(issuer)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// getLoadBalancerAddress returns the address assigned to the LoadBalancer of the Dex Service
+func (r *ReconcileDexConfiguration) getLoadBalancerAddress(instance *kubicv1beta1.DexConfiguration) (string, error) {
+	service := newDexService(instance)
+	current, err := r.Clientset.CoreV1().Services(service.GetNamespace()).Get(service.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	for _, ingress := range current.Status.LoadBalancer.Ingress {
+		if len(ingress.IP) > 0 {
+			return ingress.IP, nil
+		}
+		if len(ingress.Hostname) > 0 {
+			return ingress.Hostname, nil
+		}
+	}
+	return "", errNoLoadBalancerAddress
+}
+
+// reconcileExposure creates/updates the Service for Dex, as well as the Ingress when
+// it is exposed with an Ingress (removing it in any other case). This must be done
+// before rendering the configuration, as the issuer can depend on the address
+// assigned to the Service.
+func (r *ReconcileDexConfiguration) reconcileExposure(instance *kubicv1beta1.DexConfiguration, deployment *Deployment) error {
+	glog.V(3).Infof("[kubic] exposing Dex with a %s", getExposeMode(instance))
+	if err := createOrUpdateDexService(r.Clientset, instance, deployment.GetName()); err != nil {
+		glog.V(5).Infof("[kubic] could not create/update Service: %s", err)
+		return err
+	}
+
+	if getExposeMode(instance) == kubicv1beta1.DexExposeIngress {
+		if err := createOrUpdateDexIngress(r.Clientset, instance); err != nil {
+			glog.V(5).Infof("[kubic] could not create/update Ingress: %s", err)
+			return err
+		}
+	} else if err := deleteDexIngress(r.Clientset, instance); err != nil {
+		return err
+	}
+	return nil
+}
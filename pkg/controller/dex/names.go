@@ -0,0 +1,73 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dex
+
+import (
+	"fmt"
+
+	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
+	dexcfg "github.com/kubic-project/dex-operator/pkg/config"
+	"github.com/kubic-project/dex-operator/pkg/util"
+)
+
+// isMainInstance returns true for the DexConfiguration that was the only
+// instance supported by previous versions of the operator
+func isMainInstance(instance *kubicv1beta1.DexConfiguration) bool {
+	return instance.GetName() == dexcfg.DefaultConfigurationName
+}
+
+// getPrefix returns the prefix for the names of the objects created for a DexConfiguration.
+// The main instance uses the global prefix, so the objects created by previous versions
+// of the operator keep their names.
+func getPrefix(instance *kubicv1beta1.DexConfiguration) string {
+	return getInstanceName(instance, dexcfg.DefaultPrefix, "-")
+}
+
+// getNamespace returns the namespace where Dex (and all the objects it needs) is run
+// for a DexConfiguration
+func getNamespace(instance *kubicv1beta1.DexConfiguration) string {
+	if len(instance.Spec.Namespace) > 0 {
+		return instance.Spec.Namespace
+	}
+	return dexcfg.DefaultNamespace
+}
+
+// getDeployedNamespace returns the namespace where the objects for a DexConfiguration
+// were created, or an empty string when nothing has been created yet. Previous versions
+// of the operator did not record the namespace, so it is obtained from the Deployment
+// or the configuration in that case.
+func getDeployedNamespace(instance *kubicv1beta1.DexConfiguration) string {
+	switch {
+	case len(instance.Status.Namespace) > 0:
+		return instance.Status.Namespace
+	case len(instance.Status.Deployment) > 0:
+		return util.StringToNamespacedName(instance.Status.Deployment).Namespace
+	case len(instance.Status.Config) > 0:
+		return util.StringToNamespacedName(instance.Status.Config).Namespace
+	}
+	return ""
+}
+
+// getInstanceName returns `name` for the main instance, or `name` followed by
+// the name of the DexConfiguration (separated by `sep`) for any other instance
+func getInstanceName(instance *kubicv1beta1.DexConfiguration, name, sep string) string {
+	if isMainInstance(instance) {
+		return name
+	}
+	return fmt.Sprintf("%s%s%s", name, sep, util.SafeID(instance.GetName()))
+}
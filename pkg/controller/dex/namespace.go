@@ -0,0 +1,131 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dex
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
+)
+
+// reconcileNamespace moves Dex to the namespace in the spec when the objects for a
+// DexConfiguration were created in a different namespace (ie, when `spec.namespace`
+// has been changed, or when upgrading from a version that always used "kube-system").
+// Things that cannot be regenerated (the static clients passwords and the configurations
+// kept for rollbacks) are copied to the new namespace, and everything else is removed
+// from the old namespace, so it is created again by the rest of the reconciliation.
+func (r *ReconcileDexConfiguration) reconcileNamespace(instance *kubicv1beta1.DexConfiguration, staticClientsNames []string) error {
+	oldNamespace, newNamespace := getDeployedNamespace(instance), getNamespace(instance)
+	if len(oldNamespace) == 0 || oldNamespace == newNamespace {
+		return nil
+	}
+
+	glog.V(3).Infof("[kubic] moving Dex from namespace '%s' to '%s'", oldNamespace, newNamespace)
+	r.EventRecorder.Event(instance, corev1.EventTypeNormal,
+		"Migrating", fmt.Sprintf("Moving Dex from namespace '%s' to '%s'...", oldNamespace, newNamespace))
+
+	// a copy of the instance for the objects in the old namespace
+	old := instance.DeepCopy()
+	old.Spec.Namespace = oldNamespace
+
+	if err := createDexNamespace(r.Clientset, instance); err != nil {
+		return err
+	}
+
+	// copy the static clients passwords, so clients do not need to be reconfigured
+	oldPasswords, err := NewStaticClientsPasswords(getPrefix(old), oldNamespace)
+	if err != nil {
+		return err
+	}
+	if err = oldPasswords.GetOrRandomFromSecrets(r.Clientset, staticClientsNames); err != nil {
+		return err
+	}
+	newPasswords, err := NewStaticClientsPasswords(getPrefix(instance), newNamespace)
+	if err != nil {
+		return err
+	}
+	for name, password := range oldPasswords.Passwords {
+		newPasswords.Passwords[name] = password.InNamespace(newNamespace)
+	}
+	if err = newPasswords.CreateOrUpdateToSecrets(r.Clientset); err != nil {
+		return err
+	}
+
+	// copy the configurations kept for rollbacks
+	oldRevisions, err := NewConfigRevisionsFor(old, r)
+	if err != nil {
+		return err
+	}
+	newRevisions, err := NewConfigRevisionsFor(instance, r)
+	if err != nil {
+		return err
+	}
+	if err = newRevisions.CreateLocalFrom(oldRevisions); err != nil {
+		return err
+	}
+	if err = r.setOwner(instance, newRevisions); err != nil {
+		return err
+	}
+	if newRevisions.NeedsCreateOrUpdate() {
+		if err = newRevisions.CreateOrUpdate(); err != nil {
+			return err
+		}
+	}
+
+	// remove everything in the old namespace
+	oldDeployment, err := NewDeploymentFor(old, r)
+	if err != nil {
+		return err
+	}
+	oldConfigMap, err := NewDexConfigMapFor(old, r)
+	if err != nil {
+		return err
+	}
+	// reconcileRemoval() ignores deletion errors, but the Deployment and the objects it needs
+	// must be removed before forgetting about the old namespace (or they would be leaked)
+	if err = oldDeployment.Delete(); err != nil {
+		return err
+	}
+	if err = r.reconcileRemoval(old, oldDeployment, oldConfigMap, oldPasswords); err != nil {
+		return err
+	}
+
+	// the certificate we generated is not valid in the new namespace
+	// (the name of the Service is one of its SANs)
+	if cert := instance.Status.GeneratedCertificate; len(cert.Name) > 0 && cert.Namespace == oldNamespace {
+		glog.V(3).Infof("[kubic] removing certificate '%s/%s'", cert.Namespace, cert.Name)
+		err = r.Clientset.CoreV1().Secrets(cert.Namespace).Delete(cert.Name, &metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	// keep the revisions, as they have been copied
+	revisions, failedConfigHashes := instance.Status.Revisions, instance.Status.FailedConfigHashes
+	instance.Status = old.Status
+	instance.Status.Revisions, instance.Status.FailedConfigHashes = revisions, failedConfigHashes
+
+	r.EventRecorder.Event(instance, corev1.EventTypeNormal,
+		"Migrating", fmt.Sprintf("Dex removed from namespace '%s'", oldNamespace))
+	return nil
+}
@@ -0,0 +1,198 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dex
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/golang/glog"
+	"github.com/kubernetes/kubernetes/cmd/kubeadm/app/util/apiclient"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
+	"github.com/kubic-project/dex-operator/pkg/util"
+)
+
+// ConfigRevisions is a Secret where the last Dex configurations successfully
+// rolled out are stored (indexed by their hash), so they can be restored when
+// a new configuration breaks Dex.
+type ConfigRevisions struct {
+	instance *kubicv1beta1.DexConfiguration
+
+	current    *corev1.Secret
+	generated  *corev1.Secret
+	reconciler *ReconcileDexConfiguration
+}
+
+// NewConfigRevisionsFor returns a new dex.ConfigRevisions
+func NewConfigRevisionsFor(instance *kubicv1beta1.DexConfiguration, reconciler *ReconcileDexConfiguration) (*ConfigRevisions, error) {
+	revs := &ConfigRevisions{
+		instance:   instance,
+		reconciler: reconciler,
+	}
+
+	if err := revs.GetFrom(instance); err != nil {
+		return nil, err
+	}
+	return revs, nil
+}
+
+// GetFrom obtains the current Secret with the revisions
+func (revs *ConfigRevisions) GetFrom(instance *kubicv1beta1.DexConfiguration) error {
+	var err error
+
+	revs.current, err = revs.reconciler.Clientset.CoreV1().Secrets(revs.GetNamespace()).Get(revs.GetName(), metav1.GetOptions{})
+	if err != nil {
+		revs.current = nil
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get returns the Dex configuration stored for a hash
+func (revs ConfigRevisions) Get(hash string) (string, bool) {
+	if revs.current == nil {
+		return "", false
+	}
+	data, found := revs.current.Data[hash]
+	return string(data), found
+}
+
+// CreateLocal generates a local Secret with the Dex configuration `data` (with hash `hash`)
+// added to the current revisions. Only the revisions in the instance.Status are kept.
+// Note well that this instance is not published to the apiserver: users must use
+// `CreateOrUpdate()` for doing that.
+func (revs *ConfigRevisions) CreateLocal(hash, data string) error {
+	glog.V(3).Infof("[kubic] generating local Secret with revisions of the Dex configuration")
+
+	revsData := revs.keptFrom(revs.current)
+	revsData[hash] = []byte(data)
+
+	revs.generated = &corev1.Secret{
+		ObjectMeta: util.NamaspacedObjToMeta(revs),
+		Type:       corev1.SecretTypeOpaque,
+		Data:       revsData,
+	}
+	return nil
+}
+
+// CreateLocalFrom generates a local Secret with the revisions stored in `other`
+// (ie, in a different namespace). Only the revisions in the instance.Status are kept.
+func (revs *ConfigRevisions) CreateLocalFrom(other *ConfigRevisions) error {
+	glog.V(3).Infof("[kubic] generating local Secret with revisions of the Dex configuration from '%s'", other)
+
+	revs.generated = &corev1.Secret{
+		ObjectMeta: util.NamaspacedObjToMeta(revs),
+		Type:       corev1.SecretTypeOpaque,
+		Data:       revs.keptFrom(other.current),
+	}
+	return nil
+}
+
+// keptFrom returns the data in `secret` for the revisions in the instance.Status
+func (revs ConfigRevisions) keptFrom(secret *corev1.Secret) map[string][]byte {
+	keep := map[string]bool{}
+	for _, rev := range revs.instance.Status.Revisions {
+		keep[rev.ConfigHash] = true
+	}
+
+	res := map[string][]byte{}
+	if secret != nil {
+		for k, v := range secret.Data {
+			if keep[k] {
+				res[k] = v
+			}
+		}
+	}
+	return res
+}
+
+// NeedsCreateOrUpdate returns true if the Secret is not in the cluster or it needs to be updated
+// CreateLocal() must have been previously
+func (revs ConfigRevisions) NeedsCreateOrUpdate() bool {
+	if revs.generated == nil {
+		panic("ConfigRevisions have not been generated")
+	}
+	if revs.current == nil {
+		return true
+	}
+	return !reflect.DeepEqual(revs.generated.Data, revs.current.Data)
+}
+
+// CreateOrUpdate creates the Secret in the apiserver, or updates an existing instance
+func (revs *ConfigRevisions) CreateOrUpdate() error {
+	var err error
+
+	if revs.generated == nil {
+		// this would be an error in our program's logic
+		panic("ConfigRevisions have not been generated")
+	}
+
+	glog.V(3).Infof("[kubic] creating/updating Secret '%s'", util.NamespacedObjToString(revs))
+	if err = apiclient.CreateOrUpdateSecret(revs.reconciler.Clientset, revs.generated); err != nil {
+		glog.V(3).Infof("[kubic] could not create/update Secret '%s': %s", util.NamespacedObjToString(revs), err)
+		return err
+	}
+
+	revs.current, err = revs.reconciler.Clientset.CoreV1().Secrets(revs.GetNamespace()).Get(revs.GetName(), metav1.GetOptions{})
+	if err != nil {
+		revs.current = nil
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes the current Secret
+func (revs *ConfigRevisions) Delete() error {
+	err := revs.reconciler.Clientset.CoreV1().Secrets(revs.GetNamespace()).Delete(revs.GetName(), &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	revs.current = nil
+	return nil
+}
+
+// GetObject returns the metav1.Object generated for the dex.ConfigRevisions
+func (revs *ConfigRevisions) GetObject() metav1.Object {
+	if revs.generated == nil {
+		panic("needs to be generated first")
+	}
+	return revs.generated
+}
+
+// GetName returns the name of the Secret
+func (revs ConfigRevisions) GetName() string {
+	return fmt.Sprintf("%s-config-revisions", getPrefix(revs.instance))
+}
+
+// GetNamespace returns the namespace where Dex is run
+func (revs ConfigRevisions) GetNamespace() string {
+	return getNamespace(revs.instance)
+}
+
+// String returns the namespaceObj as a string
+func (revs ConfigRevisions) String() string {
+	return util.NamespacedObjToString(revs)
+}
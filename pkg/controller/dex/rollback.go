@@ -0,0 +1,209 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dex
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
+	"github.com/kubic-project/dex-operator/pkg/util"
+)
+
+// rejectConnectors removes from `connectors` the connectors rejected in previous
+// rollbacks, updating their status. Connectors are rejected in a specific generation,
+// so they will be tried again once they are modified.
+func (r *ReconcileDexConfiguration) rejectConnectors(instance *kubicv1beta1.DexConfiguration, connectors *Connectors) error {
+	if len(instance.Status.RejectedConnectors) == 0 {
+		return nil
+	}
+
+	rejected := connectors.Remove(func(obj connectorObject) bool {
+		return util.ContainsString(instance.Status.RejectedConnectors, connectorKey(obj))
+	})
+
+	// forget about connectors that have been modified or removed
+	instance.Status.RejectedConnectors = []string{}
+	for _, obj := range rejected {
+		key := connectorKey(obj)
+		instance.Status.RejectedConnectors = append(instance.Status.RejectedConnectors, key)
+
+		message := fmt.Sprintf("connector rejected: it broke the rollout of Dex (generation %d)", obj.GetGeneration())
+		glog.V(3).Infof("[kubic] %s ignored: %s", key, message)
+		if err := r.setConnectorRejected(instance, obj, message); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isFailedConfig returns true if a configuration has been rolled back in the past
+func isFailedConfig(instance *kubicv1beta1.DexConfiguration, hash string) bool {
+	return util.ContainsString(instance.Status.FailedConfigHashes, hash)
+}
+
+// reconcileRevisions records the configuration in a rollout that has been completed
+// as a good revision, or rolls back to the last good revision when the rollout has failed
+func (r *ReconcileDexConfiguration) reconcileRevisions(instance *kubicv1beta1.DexConfiguration,
+	configMap *ConfigMap, deployment *Deployment) error {
+
+	progressing := getCondition(instance.Status.Conditions, kubicv1beta1.DexProgressing)
+	if progressing == nil || len(instance.Status.Rollout.ConfigHash) == 0 {
+		return nil
+	}
+
+	switch progressing.Reason {
+	case "RolloutComplete":
+		return r.recordGoodRevision(instance, configMap)
+	case deploymentProgressDeadlineExceededReason:
+		hash := instance.Status.Rollout.ConfigHash
+		if isFailedConfig(instance, hash) {
+			return nil // we have already rolled it back
+		}
+		if len(instance.Status.Revisions) > 0 && instance.Status.Revisions[0].ConfigHash == hash {
+			return nil // the configuration was fine: the problem must be somewhere else
+		}
+		return r.rollback(instance, configMap, deployment)
+	}
+	return nil
+}
+
+// recordGoodRevision stores the configuration that has been successfully rolled out
+// in the list of revisions
+func (r *ReconcileDexConfiguration) recordGoodRevision(instance *kubicv1beta1.DexConfiguration, configMap *ConfigMap) error {
+	rollout := instance.Status.Rollout
+	if len(instance.Status.Revisions) > 0 && instance.Status.Revisions[0].ConfigHash == rollout.ConfigHash {
+		return nil
+	}
+
+	// the configuration could have been changed after the rollout
+	data, found := configMap.GetCurrentData()
+	if !found || fmt.Sprintf("%x", sha256.Sum256([]byte(data))) != rollout.ConfigHash {
+		return nil
+	}
+
+	glog.V(3).Infof("[kubic] Dex configuration with HASH=%s successfully rolled out", rollout.ConfigHash)
+	revisions := []kubicv1beta1.DexConfigRevision{{
+		ConfigHash: rollout.ConfigHash,
+		Connectors: rollout.Connectors,
+		Time:       metav1.Now(),
+	}}
+	for _, rev := range instance.Status.Revisions {
+		if len(revisions) >= instance.Spec.RevisionHistoryLimit {
+			break
+		}
+		if rev.ConfigHash != rollout.ConfigHash {
+			revisions = append(revisions, rev)
+		}
+	}
+	instance.Status.Revisions = revisions
+
+	failed := []string{}
+	for _, hash := range instance.Status.FailedConfigHashes {
+		if hash != rollout.ConfigHash {
+			failed = append(failed, hash)
+		}
+	}
+	instance.Status.FailedConfigHashes = failed
+
+	revs, err := NewConfigRevisionsFor(instance, r)
+	if err != nil {
+		return err
+	}
+	if err = revs.CreateLocal(rollout.ConfigHash, data); err != nil {
+		return err
+	}
+	if err = r.setOwner(instance, revs); err != nil {
+		return err
+	}
+	return revs.CreateOrUpdate()
+}
+
+// rollback restores the last good configuration after a failed rollout, rejecting
+// the connectors that were not present in that configuration
+func (r *ReconcileDexConfiguration) rollback(instance *kubicv1beta1.DexConfiguration,
+	configMap *ConfigMap, deployment *Deployment) error {
+
+	rollout := instance.Status.Rollout
+
+	var good *kubicv1beta1.DexConfigRevision
+	for i := range instance.Status.Revisions {
+		if instance.Status.Revisions[i].ConfigHash != rollout.ConfigHash {
+			good = &instance.Status.Revisions[i]
+			break
+		}
+	}
+	if good == nil {
+		glog.V(3).Infof("[kubic] no previous Dex configuration to roll back to")
+		return nil
+	}
+
+	revs, err := NewConfigRevisionsFor(instance, r)
+	if err != nil {
+		return err
+	}
+	data, found := revs.Get(good.ConfigHash)
+	if !found {
+		glog.V(3).Infof("[kubic] cannot roll back Dex configuration: HASH=%s not found in '%s'", good.ConfigHash, revs)
+		return nil
+	}
+
+	// do not try this configuration again
+	instance.Status.FailedConfigHashes = append(instance.Status.FailedConfigHashes, rollout.ConfigHash)
+	if extra := len(instance.Status.FailedConfigHashes) - instance.Spec.RevisionHistoryLimit; extra > 0 {
+		instance.Status.FailedConfigHashes = instance.Status.FailedConfigHashes[extra:]
+	}
+
+	// reject the connectors that were not present in the good configuration
+	rejected := []string{}
+	for _, key := range rollout.Connectors {
+		if !util.ContainsString(good.Connectors, key) {
+			rejected = append(rejected, key)
+		}
+	}
+	instance.Status.RejectedConnectors = util.RemoveDuplicates(append(instance.Status.RejectedConnectors, rejected...))
+
+	glog.V(3).Infof("[kubic] rolling back Dex configuration from HASH=%s to HASH=%s", rollout.ConfigHash, good.ConfigHash)
+	configMap.CreateLocalFrom(data)
+	if err = r.setOwner(instance, configMap); err != nil {
+		return err
+	}
+	if err = configMap.CreateOrUpdate(); err != nil {
+		return err
+	}
+	if err = deployment.SetCurrentConfigHash(good.ConfigHash); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("configuration %s failed to roll out: rolled back to configuration %s", rollout.ConfigHash, good.ConfigHash)
+	if len(rejected) > 0 {
+		message = fmt.Sprintf("%s (rejected connectors: %s)", message, strings.Join(rejected, ", "))
+	}
+	r.EventRecorder.Event(instance, corev1.EventTypeWarning, "RolledBack", message)
+
+	startInstanceRollout(instance, good.ConfigHash, good.Connectors)
+	setInstanceCondition(instance, kubicv1beta1.DexProgressing, corev1.ConditionTrue, "RollingBack", message)
+	setInstanceCondition(instance, kubicv1beta1.DexConfigRendered, corev1.ConditionFalse, "RolledBack", message)
+	return nil
+}
@@ -0,0 +1,185 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package dex
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+
+	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
+)
+
+// setInstanceCondition sets a condition in the status of a DexConfiguration
+func setInstanceCondition(instance *kubicv1beta1.DexConfiguration, t kubicv1beta1.ConditionType,
+	status corev1.ConditionStatus, reason, message string) {
+	instance.Status.Conditions = setCondition(instance.Status.Conditions, t, status, reason, message)
+}
+
+// setInstanceConditionFromError sets a condition to True when `err` is nil, or False otherwise
+func setInstanceConditionFromError(instance *kubicv1beta1.DexConfiguration, t kubicv1beta1.ConditionType,
+	reason string, err error) {
+	if err != nil {
+		setInstanceCondition(instance, t, corev1.ConditionFalse, reason, err.Error())
+	} else {
+		setInstanceCondition(instance, t, corev1.ConditionTrue, reason, "")
+	}
+}
+
+// updateInstanceConditions sets the conditions that summarize the state of the DexConfiguration
+// after a reconciliation that finished with `reconcileErr`
+func updateInstanceConditions(instance *kubicv1beta1.DexConfiguration, deployment *Deployment, reconcileErr error) {
+	if available, message := deployment.IsAvailable(); available {
+		setInstanceCondition(instance, kubicv1beta1.DexDeploymentAvailable, corev1.ConditionTrue, "MinimumReplicasAvailable", message)
+	} else {
+		setInstanceCondition(instance, kubicv1beta1.DexDeploymentAvailable, corev1.ConditionFalse, "MinimumReplicasUnavailable", message)
+	}
+
+	if reconcileErr != nil {
+		setInstanceCondition(instance, kubicv1beta1.DexDegraded, corev1.ConditionTrue, "ReconcileError", reconcileErr.Error())
+	} else {
+		setInstanceCondition(instance, kubicv1beta1.DexDegraded, corev1.ConditionFalse, "ReconcileSuccess", "")
+	}
+
+	conditions := instance.Status.Conditions
+	switch {
+	case instance.Status.NumConnectors == 0:
+		setInstanceCondition(instance, kubicv1beta1.DexReady, corev1.ConditionFalse, "NoConnectors", "no connectors available")
+	case reconcileErr != nil:
+		setInstanceCondition(instance, kubicv1beta1.DexReady, corev1.ConditionFalse, "Degraded", reconcileErr.Error())
+	case !isConditionTrue(conditions, kubicv1beta1.DexConfigRendered):
+		setInstanceCondition(instance, kubicv1beta1.DexReady, corev1.ConditionFalse, "ConfigNotRendered", "")
+	case !isConditionTrue(conditions, kubicv1beta1.DexCertificateReady):
+		setInstanceCondition(instance, kubicv1beta1.DexReady, corev1.ConditionFalse, "CertificateNotReady", "")
+	case !isConditionTrue(conditions, kubicv1beta1.DexDeploymentAvailable):
+		setInstanceCondition(instance, kubicv1beta1.DexReady, corev1.ConditionFalse, "DeploymentNotAvailable", "")
+	case !isConditionTrue(conditions, kubicv1beta1.DexProgressing):
+		setInstanceCondition(instance, kubicv1beta1.DexReady, corev1.ConditionFalse, "RolloutFailed", "")
+	case !deployment.IsRolloutComplete():
+		setInstanceCondition(instance, kubicv1beta1.DexReady, corev1.ConditionFalse, "RolloutInProgress", "")
+	default:
+		setInstanceCondition(instance, kubicv1beta1.DexReady, corev1.ConditionTrue, "Running", "")
+	}
+}
+
+// startInstanceRollout records the start of the rollout of the configuration with hash `hash`,
+// which contains the `connectors`
+func startInstanceRollout(instance *kubicv1beta1.DexConfiguration, hash string, connectors []string) {
+	glog.V(3).Infof("[kubic] rollout of Dex configuration with HASH=%s started", hash)
+	now := metav1.Now()
+	instance.Status.Rollout = kubicv1beta1.DexRolloutStatus{
+		ConfigHash: hash,
+		Connectors: connectors,
+		StartTime:  &now,
+	}
+	setInstanceCondition(instance, kubicv1beta1.DexProgressing, corev1.ConditionTrue, "NewConfiguration", "")
+}
+
+// updateInstanceRollout updates the rollout status of the DexConfiguration from the current
+// Deployment, emitting a Warning event when the rollout does not progress in time.
+// It returns the time after which the rollout should be checked again (or 0 if there
+// is no need to check it).
+func (r *ReconcileDexConfiguration) updateInstanceRollout(instance *kubicv1beta1.DexConfiguration, deployment *Deployment) time.Duration {
+	rollout := &instance.Status.Rollout
+
+	if deployment.current == nil {
+		*rollout = kubicv1beta1.DexRolloutStatus{}
+		setInstanceCondition(instance, kubicv1beta1.DexProgressing, corev1.ConditionFalse, "NoDeployment", "Deployment not found")
+		return 0
+	}
+
+	// a new configuration means a new rollout (maybe started by someone else)
+	if hash := deployment.GetCurrentConfigHash(); hash != rollout.ConfigHash || rollout.StartTime == nil {
+		startInstanceRollout(instance, hash, nil)
+	}
+
+	status := deployment.current.Status
+	rollout.Replicas = status.Replicas
+	rollout.UpdatedReplicas = status.UpdatedReplicas
+	rollout.ReadyReplicas = status.ReadyReplicas
+	rollout.AvailableReplicas = status.AvailableReplicas
+	progress := fmt.Sprintf("%d of %d updated replicas available", status.AvailableReplicas, status.UpdatedReplicas)
+
+	prev := getCondition(instance.Status.Conditions, kubicv1beta1.DexProgressing)
+
+	if deployment.IsRolloutComplete() {
+		if prev == nil || prev.Reason != "RolloutComplete" {
+			r.EventRecorder.Event(instance, corev1.EventTypeNormal, "RolloutComplete",
+				fmt.Sprintf("Dex Deployment '%s' rolled out: %s", deployment.GetName(), progress))
+		}
+		setInstanceCondition(instance, kubicv1beta1.DexProgressing, corev1.ConditionTrue, "RolloutComplete", progress)
+		return 0
+	}
+
+	if exceeded, message := deployment.IsProgressDeadlineExceeded(); exceeded {
+		msg := fmt.Sprintf("rollout of Dex Deployment '%s' has not progressed in %ds (%s): %s",
+			deployment.GetName(), instance.Spec.ProgressDeadlineSeconds, progress, message)
+		if prev == nil || prev.Reason != deploymentProgressDeadlineExceededReason {
+			glog.V(3).Infof("[kubic] %s", msg)
+			r.EventRecorder.Event(instance, corev1.EventTypeWarning, "RolloutFailed", msg)
+		}
+		setInstanceCondition(instance, kubicv1beta1.DexProgressing, corev1.ConditionFalse, deploymentProgressDeadlineExceededReason, msg)
+		return 0
+	}
+
+	setInstanceCondition(instance, kubicv1beta1.DexProgressing, corev1.ConditionTrue, "RolloutInProgress", progress)
+
+	// check again once the deadline has passed (the Deployment should have been updated by then)
+	deadline := rollout.StartTime.Add(time.Duration(instance.Spec.ProgressDeadlineSeconds) * time.Second)
+	if remaining := time.Until(deadline); remaining > 0 {
+		return remaining
+	}
+	return time.Duration(instance.Spec.ProgressDeadlineSeconds) * time.Second
+}
+
+// updateInstanceStatus saves the status of a DexConfiguration through the status subresource.
+// On conflicts, the status is saved in the latest version of the instance.
+func (r *ReconcileDexConfiguration) updateInstanceStatus(instance *kubicv1beta1.DexConfiguration) error {
+	ctx := context.Background()
+	nname := types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+
+	instance.Status.ObservedGeneration = instance.GetGeneration()
+	status := instance.Status.DeepCopy()
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		err := r.Status().Update(ctx, instance)
+		switch {
+		case err == nil:
+			return nil
+		case apierrors.IsConflict(err):
+			glog.V(5).Infof("[kubic] conflict when updating status of '%s': retrying", instance.GetName())
+			if err := r.Get(ctx, nname, instance); err != nil {
+				return err
+			}
+			instance.Status = *status
+			return err // (the conflict, so it is retried)
+		case apierrors.IsNotFound(err):
+			// the instance has been removed
+			return nil
+		default:
+			return fmt.Errorf("could not update status of '%s': %s", instance.GetName(), err)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package crypto
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+var (
+	csrGroupVersionV1      = schema.GroupVersion{Group: "certificates.k8s.io", Version: "v1"}
+	csrGroupVersionV1beta1 = schema.GroupVersion{Group: "certificates.k8s.io", Version: "v1beta1"}
+)
+
+// CSRClient manages CertificateSigningRequests with the newest version of the
+// certificates.k8s.io API served by the apiserver
+type CSRClient struct {
+	resource     dynamic.NamespaceableResourceInterface
+	groupVersion schema.GroupVersion
+}
+
+// NewCSRClient creates a new CSRClient, using API discovery for choosing the API version
+func NewCSRClient(cli clientset.Interface, dyn dynamic.Interface) *CSRClient {
+	gv := csrGroupVersionV1beta1
+	if _, err := cli.Discovery().ServerResourcesForGroupVersion(csrGroupVersionV1.String()); err == nil {
+		gv = csrGroupVersionV1
+	}
+	glog.V(5).Infof("[kubic] using %s for CertificateSigningRequests", gv)
+
+	return &CSRClient{
+		resource:     dyn.Resource(gv.WithResource("certificatesigningrequests")),
+		groupVersion: gv,
+	}
+}
+
+// IsV1 returns true if the certificates.k8s.io/v1 API is used
+func (c CSRClient) IsV1() bool {
+	return c.groupVersion == csrGroupVersionV1
+}
+
+// Get gets a CSR, returning nil if it does not exist
+func (c CSRClient) Get(name string) (*unstructured.Unstructured, error) {
+	csr, err := c.resource.Get(name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return csr, err
+}
+
+// Create creates a new CSR for a PEM-encoded certificate request
+func (c CSRClient) Create(name string, request []byte, usages []string, signerName string) (*unstructured.Unstructured, error) {
+	csr := newCSRObject(c.groupVersion, name, request, usages, signerName)
+	return c.resource.Create(csr)
+}
+
+// Approve approves a CSR
+func (c CSRClient) Approve(csr *unstructured.Unstructured) error {
+	conditions, _, err := unstructured.NestedSlice(csr.Object, "status", "conditions")
+	if err != nil {
+		return err
+	}
+	conditions = append(conditions, map[string]interface{}{
+		"type":           "Approved",
+		"status":         "True",
+		"reason":         "AutoApproved",
+		"message":        "This CSR was approved by the Kubic Service certificates generator.",
+		"lastUpdateTime": time.Now().UTC().Format(time.RFC3339),
+	})
+	if err := unstructured.SetNestedSlice(csr.Object, conditions, "status", "conditions"); err != nil {
+		return err
+	}
+
+	_, err = c.resource.Update(csr, "approval")
+	return err
+}
+
+// Delete deletes a CSR
+func (c CSRClient) Delete(name string) error {
+	err := c.resource.Delete(name, &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// newCSRObject returns a new CertificateSigningRequest object
+func newCSRObject(gv schema.GroupVersion, name string, request []byte, usages []string, signerName string) *unstructured.Unstructured {
+	usagesList := []interface{}{}
+	for _, usage := range usages {
+		usagesList = append(usagesList, usage)
+	}
+
+	spec := map[string]interface{}{
+		"request": base64.StdEncoding.EncodeToString(request),
+		"usages":  usagesList,
+	}
+	if len(signerName) > 0 {
+		spec["signerName"] = signerName
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": gv.String(),
+			"kind":       "CertificateSigningRequest",
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+			"spec": spec,
+		},
+	}
+}
+
+// getCSRCertificate returns the (PEM-encoded) certificate in a CSR, or nil if it
+// has not been approved and signed yet. An error is returned if the CSR has been
+// denied or the signer has failed.
+func getCSRCertificate(csr *unstructured.Unstructured) ([]byte, error) {
+	conditions, _, err := unstructured.NestedSlice(csr.Object, "status", "conditions")
+	if err != nil {
+		return nil, err
+	}
+
+	approved := false
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch condition["type"] {
+		case "Approved":
+			approved = true
+		case "Denied", "Failed":
+			return nil, fmt.Errorf("certificate signing request '%s' %s: %v",
+				csr.GetName(), condition["type"], condition["message"])
+		}
+	}
+	if !approved {
+		return nil, nil
+	}
+
+	encoded, _, err := unstructured.NestedString(csr.Object, "status", "certificate")
+	if err != nil || len(encoded) == 0 {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(encoded)
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package crypto
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNewCSRObject(t *testing.T) {
+	csr := newCSRObject(csrGroupVersionV1, "dex-csr", []byte("request"), serverCertificateUsages, "example.com/my-signer")
+	if csr.GetAPIVersion() != "certificates.k8s.io/v1" || csr.GetName() != "dex-csr" {
+		t.Fatalf("unexpected CSR: %+v", csr.Object)
+	}
+	if signer, _, _ := unstructured.NestedString(csr.Object, "spec", "signerName"); signer != "example.com/my-signer" {
+		t.Fatalf("unexpected signer: %q", signer)
+	}
+	if request, _, _ := unstructured.NestedString(csr.Object, "spec", "request"); request != base64.StdEncoding.EncodeToString([]byte("request")) {
+		t.Fatalf("unexpected request: %q", request)
+	}
+
+	csr = newCSRObject(csrGroupVersionV1beta1, "dex-csr", []byte("request"), defaultCertificateUsages, "")
+	if _, found, _ := unstructured.NestedString(csr.Object, "spec", "signerName"); found {
+		t.Fatalf("signer set in CSR without signer: %+v", csr.Object)
+	}
+}
+
+func TestGetCSRCertificate(t *testing.T) {
+	csr := newCSRObject(csrGroupVersionV1, "dex-csr", []byte("request"), serverCertificateUsages, "example.com/dex-signer")
+	setStatus := func(conditionType string, certificate string) {
+		unstructured.SetNestedField(csr.Object, map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": conditionType, "status": "True", "message": "some message"},
+			},
+			"certificate": certificate,
+		}, "status")
+	}
+
+	if certificate, err := getCSRCertificate(csr); err != nil || certificate != nil {
+		t.Fatalf("unexpected result for a new CSR: %q, %v", certificate, err)
+	}
+
+	setStatus("Approved", "")
+	if certificate, err := getCSRCertificate(csr); err != nil || certificate != nil {
+		t.Fatalf("unexpected result for an approved CSR: %q, %v", certificate, err)
+	}
+
+	setStatus("Approved", base64.StdEncoding.EncodeToString([]byte("certificate")))
+	if certificate, err := getCSRCertificate(csr); err != nil || string(certificate) != "certificate" {
+		t.Fatalf("unexpected result for a signed CSR: %q, %v", certificate, err)
+	}
+
+	setStatus("Denied", "")
+	if _, err := getCSRCertificate(csr); err == nil {
+		t.Fatalf("no error for a denied CSR")
+	}
+}
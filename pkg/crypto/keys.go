@@ -0,0 +1,130 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	certutil "k8s.io/client-go/util/cert"
+
+	"github.com/kubic-project/dex-operator/pkg/util"
+)
+
+// Algorithms supported for the private keys
+const (
+	KeyRSA2048   = "RSA-2048"
+	KeyRSA3072   = "RSA-3072"
+	KeyRSA4096   = "RSA-4096"
+	KeyECDSAP256 = "ECDSA-P256"
+	KeyECDSAP384 = "ECDSA-P384"
+	KeyEd25519   = "Ed25519"
+
+	// DefaultKeyAlgorithm is the algorithm used when no algorithm is specified
+	DefaultKeyAlgorithm = KeyRSA2048
+)
+
+// SupportedKeyAlgorithms is the list of algorithms supported for the private keys
+var SupportedKeyAlgorithms = []string{
+	KeyRSA2048,
+	KeyRSA3072,
+	KeyRSA4096,
+	KeyECDSAP256,
+	KeyECDSAP384,
+	KeyEd25519,
+}
+
+// GeneratePrivateKey generates a new private key with the given algorithm
+func GeneratePrivateKey(algorithm string) (crypto.Signer, error) {
+	switch algorithm {
+	case "":
+		return GeneratePrivateKey(DefaultKeyAlgorithm)
+	case KeyRSA2048:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case KeyRSA3072:
+		return rsa.GenerateKey(rand.Reader, 3072)
+	case KeyRSA4096:
+		return rsa.GenerateKey(rand.Reader, 4096)
+	case KeyECDSAP256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case KeyECDSAP384:
+		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case KeyEd25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	}
+	return nil, fmt.Errorf("unsupported key algorithm %q", algorithm)
+}
+
+// EncodePrivateKeyPEM returns the PEM encoding of a private key
+func EncodePrivateKeyPEM(key crypto.Signer) ([]byte, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return certutil.EncodePrivateKeyPEM(k), nil
+	case *ecdsa.PrivateKey:
+		der, err := x509.MarshalECPrivateKey(k)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+	default:
+		der, err := x509.MarshalPKCS8PrivateKey(k)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+	}
+}
+
+// GetKeyAlgorithm returns the algorithm of the key in the certificate of a TLS secret
+// (for example, "RSA-1024" for a weak RSA key)
+func GetKeyAlgorithm(secret *corev1.Secret) (string, error) {
+	certs, err := certutil.ParseCertsPEM(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return "", fmt.Errorf("could not parse certificate in '%s': %s", util.NamespacedObjToString(secret), err)
+	}
+
+	switch key := certs[0].PublicKey.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA-%d", key.N.BitLen()), nil
+	case *ecdsa.PublicKey:
+		return fmt.Sprintf("ECDSA-%s", strings.Replace(key.Curve.Params().Name, "-", "", -1)), nil
+	case ed25519.PublicKey:
+		return KeyEd25519, nil
+	}
+	return "", fmt.Errorf("unknown key algorithm in '%s'", util.NamespacedObjToString(secret))
+}
+
+// IsWeakKeyAlgorithm returns true for algorithms that should not be used anymore
+// (RSA keys shorter than 2048 bits)
+func IsWeakKeyAlgorithm(algorithm string) bool {
+	var bits int
+	if _, err := fmt.Sscanf(algorithm, "RSA-%d", &bits); err == nil {
+		return bits < 2048
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package crypto
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGeneratePrivateKey(t *testing.T) {
+	for _, algorithm := range SupportedKeyAlgorithms {
+		key, err := GeneratePrivateKey(algorithm)
+		if err != nil {
+			t.Fatalf("%s: could not generate key: %s", algorithm, err)
+		}
+		if _, err := EncodePrivateKeyPEM(key); err != nil {
+			t.Fatalf("%s: could not encode key: %s", algorithm, err)
+		}
+
+		// the algorithm must be detected in a certificate with this key
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "dex"},
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		der, err := x509.CreateCertificate(nil, template, template, key.Public(), key)
+		if err != nil {
+			t.Fatalf("%s: could not create certificate: %s", algorithm, err)
+		}
+		secret := &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}}
+		detected, err := GetKeyAlgorithm(secret)
+		if err != nil {
+			t.Fatalf("%s: could not get the key algorithm: %s", algorithm, err)
+		}
+		if detected != algorithm {
+			t.Fatalf("%s: unexpected algorithm detected: %s", algorithm, detected)
+		}
+		if IsWeakKeyAlgorithm(detected) {
+			t.Fatalf("%s: considered a weak algorithm", algorithm)
+		}
+	}
+
+	if _, err := GeneratePrivateKey("RSA-1024"); err == nil {
+		t.Fatalf("no error for an unsupported algorithm")
+	}
+	if !IsWeakKeyAlgorithm("RSA-1024") {
+		t.Fatalf("RSA-1024 not considered a weak algorithm")
+	}
+}
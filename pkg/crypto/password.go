@@ -88,6 +88,14 @@ func (password SharedPassword) GetNamespace() string {
 	return util.StringToNamespacedName(password.Name).Namespace
 }
 
+// InNamespace returns a copy of the password (with the same contents) in a different namespace
+func (password SharedPassword) InNamespace(namespace string) SharedPassword {
+	moved := NewSharedPassword(password.GetName(), namespace)
+	moved.length = password.length
+	moved.contents = password.contents
+	return moved
+}
+
 // String implements the Stringer interface
 func (password SharedPassword) String() string {
 	return string(password.contents[:])
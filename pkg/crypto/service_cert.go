@@ -18,34 +18,59 @@
 package crypto
 
 import (
+	"context"
+	gocrypto "crypto"
 	"crypto/rand"
-	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"net"
 	"time"
 
 	"github.com/golang/glog"
 	"github.com/kubernetes/kubernetes/cmd/kubeadm/app/util/apiclient"
-	certsv1beta1 "k8s.io/api/certificates/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	clientset "k8s.io/client-go/kubernetes"
 	certutil "k8s.io/client-go/util/cert"
 
 	"github.com/kubic-project/dex-operator/pkg/util"
 )
 
-var defaultCertificateUsages = []certsv1beta1.KeyUsage{
+const (
+	// period between checks of a pending CSR
+	csrPollPeriod = 5 * time.Second
+)
+
+// ErrCSRPending is returned when the CSR has not been signed before the request was stopped
+var ErrCSRPending = errors.New("certificate signing request not signed yet")
+
+// ErrSignerRequired is returned when the certificates.k8s.io/v1 API is used without a signer:
+// this API does not support the "legacy-unknown" signer, and the Kubernetes signers
+// only sign certificates for kubelets
+var ErrSignerRequired = errors.New("a signer name is required by the certificates.k8s.io/v1 API")
+
+var defaultCertificateUsages = []string{
 	"digital signature",
 	"key encipherment",
 	"server auth",
 	"client auth",
 }
 
+// usages for the signers in the certificates.k8s.io/v1 API (that do not allow "client auth"
+// in serving certificates)
+var serverCertificateUsages = []string{
+	"digital signature",
+	"key encipherment",
+	"server auth",
+}
+
 // AutoCert is a certificate for a service that is automatically signed by the Kubernetes CA.
 type AutoCert struct {
 	// Alternative IPs in the certificate
@@ -60,6 +85,15 @@ type AutoCert struct {
 	// ... with namespace
 	SecretNamespace string
 
+	// Renew the certificate when it expires in less than this time
+	RenewBefore time.Duration
+
+	// Algorithm for the private key (one of the SupportedKeyAlgorithms)
+	KeyAlgorithm string
+
+	// Name of the signer for the CSR (required by the certificates.k8s.io/v1 API)
+	SignerName string
+
 	// current v1.Secret
 	current *corev1.Secret
 }
@@ -110,7 +144,8 @@ func (ac *AutoCert) Delete(cli clientset.Interface) error {
 }
 
 // GetOrRequest gets a certificate from the secret, or perform a new certificate request
-func (ac *AutoCert) GetOrRequest(cli clientset.Interface) (*corev1.Secret, error) {
+// (see Request for the meaning of the `ctx`)
+func (ac *AutoCert) GetOrRequest(ctx context.Context, cli clientset.Interface, csrs *CSRClient) (*corev1.Secret, error) {
 	var err error
 
 	// use the already obtained copy if we have it
@@ -122,11 +157,16 @@ func (ac *AutoCert) GetOrRequest(cli clientset.Interface) (*corev1.Secret, error
 	ac.current, err = cli.Core().Secrets(util.NamaspacedObjToMeta(ac).Namespace).Get(util.NamaspacedObjToMeta(ac).Name, metav1.GetOptions{})
 	if err == nil {
 		glog.V(3).Infof("[kubic] TLS secret %q already present in the apiserver", ac.SecretName)
-		// TODO: we should check the certificate is still valid
+		if NeedsRenewal(ac.current, ac.RenewBefore) {
+			glog.V(3).Infof("[kubic] TLS secret %q expires in less than %s: renewing", ac.SecretName, ac.RenewBefore)
+			if ac.current, err = ac.Request(ctx, cli, csrs); err != nil {
+				return nil, err
+			}
+		}
 	} else {
 		if apierrors.IsNotFound(err) {
 			// ... and, if it is not there, request it from the apiserver
-			if ac.current, err = ac.Request(cli); err != nil {
+			if ac.current, err = ac.Request(ctx, cli, csrs); err != nil {
 				return nil, err
 			}
 		} else {
@@ -138,125 +178,136 @@ func (ac *AutoCert) GetOrRequest(cli clientset.Interface) (*corev1.Secret, error
 	return ac.current, nil
 }
 
-// Refresh invalidates the local cached Secret and performs a new GetOrRequest()
-func (ac *AutoCert) Refresh(cli clientset.Interface) (*corev1.Secret, error) {
-	ac.current = nil
-	return ac.GetOrRequest(cli)
+// GetExpiration returns the expiration time (the `notAfter`) of the certificate in a TLS secret
+func GetExpiration(secret *corev1.Secret) (time.Time, error) {
+	certs, err := certutil.ParseCertsPEM(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not parse certificate in '%s': %s", util.NamespacedObjToString(secret), err)
+	}
+	return certs[0].NotAfter, nil
 }
 
-// Request sends a CSR to the apiserver, requesting auto-approval and waiting until it is approved
-func (ac *AutoCert) Request(cli clientset.Interface) (*corev1.Secret, error) {
-
-	csrName := fmt.Sprintf("%s-csr", ac.SecretName)
-	csrNamespace := ac.SecretNamespace
-
-	// Generate a private key, pem encode it
-	// The private key will be used to create a certificate signing request (csr)
-	// that will be submitted to a Kubernetes CA to obtain a TLS certificate.
-	glog.V(3).Infof("[kubic] generating private key for '%s'", csrName)
-	key, err := rsa.GenerateKey(rand.Reader, 1024)
+// NeedsRenewal returns true if the certificate in a TLS secret expires in less than `renewBefore`
+// (or if it cannot be parsed)
+func NeedsRenewal(secret *corev1.Secret, renewBefore time.Duration) bool {
+	notAfter, err := GetExpiration(secret)
 	if err != nil {
-		return nil, fmt.Errorf("unable to genarate the private key: %s", err)
+		glog.V(3).Infof("[kubic] %s", err)
+		return true
 	}
+	return time.Now().Add(renewBefore).After(notAfter)
+}
 
-	glog.V(3).Infof("[kubic] creating a CSR for '%s'", csrName)
-	certificateRequestTemplate := x509.CertificateRequest{
-		Subject: pkix.Name{
-			CommonName: ac.Names[0],
-		},
-		SignatureAlgorithm: x509.SHA256WithRSA,
-		DNSNames:           ac.Names,
-		IPAddresses:        ac.IPs,
+// DiffSANs compares the alternative names (SANs) in the certificate of a TLS secret with some `ips`
+// and `names`, returning the SANs that are missing in the certificate and the SANs that should
+// not be there
+func DiffSANs(secret *corev1.Secret, ips []net.IP, names []string) ([]string, []string, error) {
+	certs, err := certutil.ParseCertsPEM(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not parse certificate in '%s': %s", util.NamespacedObjToString(secret), err)
 	}
 
-	certificateRequest, err := x509.CreateCertificateRequest(rand.Reader, &certificateRequestTemplate, key)
-	if err != nil {
-		return nil, fmt.Errorf("unable to generate the CSR: %s", err)
+	current := sets.NewString(certs[0].DNSNames...)
+	for _, ip := range certs[0].IPAddresses {
+		current.Insert(ip.String())
+	}
+	requested := sets.NewString(names...)
+	for _, ip := range ips {
+		requested.Insert(ip.String())
 	}
 
-	certificateRequestBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: certificateRequest})
+	return requested.Difference(current).List(), current.Difference(requested).List(), nil
+}
 
-	// Submit a certificate signing request, wait for it to be approved, then save
-	// the signed certificate to the file system.
-	certificateSigningRequest := &certsv1beta1.CertificateSigningRequest{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      csrName,
-			Namespace: csrNamespace,
-		},
-		Spec: certsv1beta1.CertificateSigningRequestSpec{
-			Groups:  []string{"system:authenticated"},
-			Request: certificateRequestBytes,
-			Usages:  defaultCertificateUsages,
-		},
-		Status: certsv1beta1.CertificateSigningRequestStatus{
-			Conditions: []certsv1beta1.CertificateSigningRequestCondition{},
-		},
+// Refresh invalidates the local cached Secret and performs a new GetOrRequest()
+func (ac *AutoCert) Refresh(ctx context.Context, cli clientset.Interface, csrs *CSRClient) (*corev1.Secret, error) {
+	ac.current = nil
+	return ac.GetOrRequest(ctx, cli, csrs)
+}
+
+// Request sends a CSR to the apiserver, requesting auto-approval and waiting until it is signed.
+// The wait is stopped when the `ctx` is done, returning ErrCSRPending: the private key is kept
+// in a Secret, so the same request can be continued in a new call.
+func (ac *AutoCert) Request(ctx context.Context, cli clientset.Interface, csrs *CSRClient) (*corev1.Secret, error) {
+	csrName := fmt.Sprintf("%s-csr", ac.SecretName)
+
+	if csrs.IsV1() && len(ac.SignerName) == 0 {
+		return nil, ErrSignerRequired
+	}
+
+	key, err := ac.getPendingKey(cli)
+	if err != nil {
+		return nil, err
 	}
 
-	glog.V(3).Infof("[kubic] submitting the CSR for '%s'", csrName)
-	certificateSigningRequest, err = cli.Certificates().CertificateSigningRequests().Create(certificateSigningRequest)
+	csr, err := csrs.Get(csrName)
 	if err != nil {
-		if apierrors.IsAlreadyExists(err) {
-			glog.V(3).Infof("[kubic] CSR '%s' already exists... getting current version", csrName)
-			certificateSigningRequest, err = cli.Certificates().CertificateSigningRequests().Get(csrName, metav1.GetOptions{})
-			if err != nil {
+		return nil, fmt.Errorf("unable to get the certificate signing request: %s", err)
+	}
+	if key == nil || csr == nil {
+		if key == nil {
+			// a CSR without a key cannot be used
+			if err := csrs.Delete(csrName); err != nil {
+				return nil, fmt.Errorf("error removing CSR: %v", err)
+			}
+
+			// Generate a private key, pem encode it
+			// The private key will be used to create a certificate signing request (csr)
+			// that will be submitted to a Kubernetes CA to obtain a TLS certificate.
+			glog.V(3).Infof("[kubic] generating %s private key for '%s'", ac.KeyAlgorithm, csrName)
+			if key, err = GeneratePrivateKey(ac.KeyAlgorithm); err != nil {
+				return nil, fmt.Errorf("unable to genarate the private key: %s", err)
+			}
+			if err = ac.savePendingKey(cli, key); err != nil {
 				return nil, err
 			}
-		} else {
-			return nil, fmt.Errorf("unable to create the certificate signing request: %s", err)
 		}
-	}
-
-	certificateSigningRequest.Status.Conditions = append(certificateSigningRequest.Status.Conditions,
-		certsv1beta1.CertificateSigningRequestCondition{
-			Type:           certsv1beta1.CertificateApproved,
-			Reason:         "AutoApproved",
-			Message:        "This CSR was approved by the Kubcic Service certificates generator.",
-			LastUpdateTime: metav1.Now(),
-		})
 
-	_, err = cli.Certificates().CertificateSigningRequests().UpdateApproval(certificateSigningRequest)
-	if err != nil {
-		return nil, fmt.Errorf("error updating approval for CSR: %v", err)
+		if csr, err = ac.submit(csrs, csrName, key); err != nil {
+			return nil, err
+		}
 	}
 
 	glog.V(3).Infof("[kubic] waiting for '%s' to be accepted and signed...", csrName)
 	var certificate []byte
-	for {
-		csr, err := cli.Certificates().CertificateSigningRequests().Get(csrName, metav1.GetOptions{})
+	err = wait.PollImmediateUntil(csrPollPeriod, func() (bool, error) {
+		if csr == nil {
+			if csr, err = csrs.Get(csrName); err != nil || csr == nil {
+				glog.V(3).Infof("[kubic] unable to retrieve CSR '%s': %v", csrName, err)
+				return false, nil
+			}
+		}
+		certificate, err = getCSRCertificate(csr)
 		if err != nil {
-			glog.V(3).Infof("[kubic] unable to retrieve CSR '%s': %s", csrName, err)
-			time.Sleep(5 * time.Second)
-			continue
+			return false, err
 		}
-
-		status := csr.Status
-		if len(status.Conditions) > 0 {
-			if status.Conditions[0].Type == certsv1beta1.CertificateApproved && len(status.Certificate) > 0 {
-				glog.V(3).Infof("[kubic] certificate '%s' is approved and signed now", csrName)
-				certificate = status.Certificate
-
-				err := cli.Certificates().CertificateSigningRequests().Delete(csrName, &metav1.DeleteOptions{})
-				if err != nil && !apierrors.IsNotFound(err) {
-					return nil, fmt.Errorf("error removing CSR: %v", err)
-				}
-
-				break
-			}
+		if len(certificate) == 0 {
+			glog.V(3).Infof("[kubic] certificate signing request '%s' not signed yet; trying again in %s", csrName, csrPollPeriod)
+			csr = nil
+			return false, nil
 		}
-
-		glog.V(3).Infof("[kubic] certificate signing request '%s' not approved yet; trying again in 5 seconds", csrName)
-		time.Sleep(5 * time.Second)
+		return true, nil
+	}, ctx.Done())
+	if err == wait.ErrWaitTimeout {
+		return nil, ErrCSRPending
+	} else if err != nil {
+		// the CSR has been denied (or has failed): start again in the next request
+		ac.deletePending(cli, csrs, csrName)
+		return nil, err
 	}
 
 	glog.V(3).Infof("[kubic] certificate '%s' signed; uploading to Secret '%s'",
 		csrName, util.NamespacedObjToString(ac))
+	keyPEM, err := EncodePrivateKeyPEM(key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode the private key: %s", err)
+	}
 	secret := &corev1.Secret{
 		ObjectMeta: util.NamaspacedObjToMeta(ac),
 		Type:       corev1.SecretTypeTLS,
 		Data: map[string][]byte{
 			corev1.TLSCertKey:       certificate,
-			corev1.TLSPrivateKeyKey: certutil.EncodePrivateKeyPEM(key),
+			corev1.TLSPrivateKeyKey: keyPEM,
 		},
 	}
 
@@ -268,6 +319,101 @@ func (ac *AutoCert) Request(cli clientset.Interface) (*corev1.Secret, error) {
 	if err != nil {
 		return nil, err
 	}
+	ac.deletePending(cli, csrs, csrName)
 
 	return secret, nil
 }
+
+// submit creates and approves a CSR for the private `key`
+func (ac *AutoCert) submit(csrs *CSRClient, csrName string, key gocrypto.Signer) (*unstructured.Unstructured, error) {
+	glog.V(3).Infof("[kubic] creating a CSR for '%s'", csrName)
+	certificateRequestTemplate := x509.CertificateRequest{
+		Subject: pkix.Name{
+			CommonName: ac.Names[0],
+		},
+		DNSNames:    ac.Names,
+		IPAddresses: ac.IPs,
+	}
+
+	certificateRequest, err := x509.CreateCertificateRequest(rand.Reader, &certificateRequestTemplate, key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate the CSR: %s", err)
+	}
+
+	certificateRequestBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: certificateRequest})
+
+	usages, signerName := defaultCertificateUsages, ac.SignerName
+	if len(signerName) > 0 {
+		usages = serverCertificateUsages
+	}
+
+	// Submit a certificate signing request, and approve it
+	glog.V(3).Infof("[kubic] submitting the CSR for '%s' (signer: %q)", csrName, signerName)
+	csr, err := csrs.Create(csrName, certificateRequestBytes, usages, signerName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the certificate signing request: %s", err)
+	}
+
+	if err = csrs.Approve(csr); err != nil {
+		return nil, fmt.Errorf("error updating approval for CSR: %v", err)
+	}
+	return csr, nil
+}
+
+// getPendingKeyName returns the name of the Secret where the private key is kept
+// while the CSR is pending
+func (ac AutoCert) getPendingKeyName() string {
+	return fmt.Sprintf("%s-csr-key", ac.SecretName)
+}
+
+// getPendingKey returns the private key of a pending CSR, or nil if there is no pending CSR
+func (ac AutoCert) getPendingKey(cli clientset.Interface) (gocrypto.Signer, error) {
+	secret, err := cli.CoreV1().Secrets(ac.SecretNamespace).Get(ac.getPendingKeyName(), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	key, err := certutil.ParsePrivateKeyPEM(secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		glog.V(3).Infof("[kubic] invalid private key in '%s': ignored", ac.getPendingKeyName())
+		return nil, nil
+	}
+	signer, ok := key.(gocrypto.Signer)
+	if !ok {
+		return nil, nil
+	}
+	return signer, nil
+}
+
+// savePendingKey saves the private key of a pending CSR
+func (ac AutoCert) savePendingKey(cli clientset.Interface, key gocrypto.Signer) error {
+	keyPEM, err := EncodePrivateKeyPEM(key)
+	if err != nil {
+		return fmt.Errorf("unable to encode the private key: %s", err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ac.getPendingKeyName(),
+			Namespace: ac.SecretNamespace,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			corev1.TLSPrivateKeyKey: keyPEM,
+		},
+	}
+	return apiclient.CreateOrUpdateSecret(cli, secret)
+}
+
+// deletePending removes the CSR and the private key saved while it was pending
+func (ac AutoCert) deletePending(cli clientset.Interface, csrs *CSRClient, csrName string) {
+	if err := csrs.Delete(csrName); err != nil {
+		glog.V(3).Infof("[kubic] could not remove CSR '%s': %s", csrName, err)
+	}
+	err := cli.CoreV1().Secrets(ac.SecretNamespace).Delete(ac.getPendingKeyName(), &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		glog.V(3).Infof("[kubic] could not remove Secret '%s': %s", ac.getPendingKeyName(), err)
+	}
+}
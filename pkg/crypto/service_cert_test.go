@@ -0,0 +1,102 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package crypto
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+func TestNeedsRenewal(t *testing.T) {
+	certPEM, keyPEM, err := certutil.GenerateSelfSignedCertKey("dex.example.com", nil, nil)
+	if err != nil {
+		t.Fatalf("could not generate certificate: %s", err)
+	}
+	secret := &corev1.Secret{
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       certPEM,
+			corev1.TLSPrivateKeyKey: keyPEM,
+		},
+	}
+
+	notAfter, err := GetExpiration(secret)
+	if err != nil {
+		t.Fatalf("could not get the expiration: %s", err)
+	}
+	if !notAfter.After(time.Now()) {
+		t.Fatalf("unexpected expiration: %s", notAfter)
+	}
+
+	if NeedsRenewal(secret, 24*time.Hour) {
+		t.Fatalf("renewal needed one day before the expiration at %s", notAfter)
+	}
+	if !NeedsRenewal(secret, time.Until(notAfter)+time.Hour) {
+		t.Fatalf("renewal not needed after the expiration at %s", notAfter)
+	}
+
+	invalid := &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: []byte("invalid")}}
+	if !NeedsRenewal(invalid, 0) {
+		t.Fatalf("renewal not needed for an invalid certificate")
+	}
+}
+
+func TestDiffSANs(t *testing.T) {
+	certPEM, _, err := certutil.GenerateSelfSignedCertKey("dex.example.com",
+		[]net.IP{net.ParseIP("10.0.0.1")}, []string{"dex.kube-system.svc"})
+	if err != nil {
+		t.Fatalf("could not generate certificate: %s", err)
+	}
+	secret := &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: certPEM}}
+
+	added, removed, err := DiffSANs(secret,
+		[]net.IP{net.ParseIP("10.0.0.1")}, []string{"dex.example.com", "dex.kube-system.svc"})
+	if err != nil {
+		t.Fatalf("could not compare SANs: %s", err)
+	}
+	if len(added) > 0 || len(removed) > 0 {
+		t.Fatalf("unexpected differences: added %v, removed %v", added, removed)
+	}
+
+	added, removed, err = DiffSANs(secret,
+		[]net.IP{net.ParseIP("10.0.0.2")}, []string{"dex.example.com", "dex.my-company.com"})
+	if err != nil {
+		t.Fatalf("could not compare SANs: %s", err)
+	}
+	if strings.Join(added, ",") != "10.0.0.2,dex.my-company.com" {
+		t.Fatalf("unexpected SANs added: %v", added)
+	}
+	if strings.Join(removed, ",") != "10.0.0.1,dex.kube-system.svc" {
+		t.Fatalf("unexpected SANs removed: %v", removed)
+	}
+}
+
+func TestRequestWithoutSigner(t *testing.T) {
+	ac := &AutoCert{SecretName: "dex-cert", SecretNamespace: "kube-system"}
+
+	// no CSR can be created with the v1 API without a signer
+	csrs := &CSRClient{groupVersion: csrGroupVersionV1}
+	if _, err := ac.Request(context.Background(), nil, csrs); err != ErrSignerRequired {
+		t.Fatalf("unexpected error without a signer: %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package defaults
+
+import (
+	"github.com/golang/glog"
+
+	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
+	dexcfg "github.com/kubic-project/dex-operator/pkg/config"
+)
+
+// SetDexConfigurationDefaults sets the default values in a DexConfiguration,
+// so the values used are visible in the object stored in the apiserver.
+// It returns true if the instance has been modified.
+func SetDexConfigurationDefaults(instance *kubicv1beta1.DexConfiguration) bool {
+	changed := false
+	spec := &instance.Spec
+
+	if len(spec.Image) == 0 {
+		spec.Image = dexcfg.DefaultImage
+		changed = true
+	}
+
+	if len(spec.Namespace) == 0 {
+		spec.Namespace = dexcfg.DefaultNamespace
+		changed = true
+	}
+
+	if len(spec.Expose.Mode) == 0 {
+		spec.Expose.Mode = kubicv1beta1.DexExposeNodePort
+		changed = true
+	}
+
+	// only the main instance gets the default NodePort, as any other instance
+	// would conflict with it: Kubernetes allocates a free port for them
+	if spec.NodePort == 0 && spec.Expose.Mode == kubicv1beta1.DexExposeNodePort &&
+		instance.GetName() == dexcfg.DefaultConfigurationName {
+		spec.NodePort = dexcfg.DefaultNodePort
+		changed = true
+	}
+
+	if spec.Replicas == nil {
+		replicas := int32(dexcfg.DefaultDeployNumReplicas)
+		spec.Replicas = &replicas
+		changed = true
+	}
+
+	if spec.ProgressDeadlineSeconds == 0 {
+		spec.ProgressDeadlineSeconds = dexcfg.DefaultProgressDeadlineSeconds
+		changed = true
+	}
+
+	if spec.RevisionHistoryLimit == 0 {
+		spec.RevisionHistoryLimit = dexcfg.DefaultRevisionHistoryLimit
+		changed = true
+	}
+
+	if len(spec.CertificateMode) == 0 {
+		spec.CertificateMode = kubicv1beta1.DexCertificateCSR
+		changed = true
+	}
+
+	if spec.CertificateRenewBeforeDays == 0 {
+		spec.CertificateRenewBeforeDays = dexcfg.DefaultCertificateRenewBeforeDays
+		changed = true
+	}
+
+	if len(spec.CertificateKeyAlgorithm) == 0 {
+		spec.CertificateKeyAlgorithm = dexcfg.DefaultCertificateKeyAlgorithm
+		changed = true
+	}
+
+	// only the main instance binds its administrators to the cluster-admin role
+	if len(spec.AdminGroup) == 0 && instance.GetName() == dexcfg.DefaultConfigurationName {
+		spec.AdminGroup = dexcfg.DefaultAdminGroup
+		changed = true
+	}
+
+	if changed {
+		glog.V(5).Infof("[kubic] defaults set in DexConfiguration '%s': %+v", instance.GetName(), *spec)
+	}
+	return changed
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package defaults
+
+import (
+	"testing"
+
+	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
+	dexcfg "github.com/kubic-project/dex-operator/pkg/config"
+)
+
+func TestSetDexConfigurationDefaults(t *testing.T) {
+	instance := &kubicv1beta1.DexConfiguration{}
+	instance.SetName(dexcfg.DefaultConfigurationName)
+	if !SetDexConfigurationDefaults(instance) {
+		t.Fatalf("defaults not set in empty instance")
+	}
+	if instance.Spec.Image != dexcfg.DefaultImage ||
+		instance.Spec.Namespace != dexcfg.DefaultNamespace ||
+		instance.Spec.NodePort != dexcfg.DefaultNodePort ||
+		instance.Spec.Expose.Mode != kubicv1beta1.DexExposeNodePort ||
+		instance.Spec.Replicas == nil || *instance.Spec.Replicas != int32(dexcfg.DefaultDeployNumReplicas) ||
+		instance.Spec.ProgressDeadlineSeconds != dexcfg.DefaultProgressDeadlineSeconds ||
+		instance.Spec.RevisionHistoryLimit != dexcfg.DefaultRevisionHistoryLimit ||
+		instance.Spec.CertificateMode != kubicv1beta1.DexCertificateCSR ||
+		instance.Spec.CertificateRenewBeforeDays != dexcfg.DefaultCertificateRenewBeforeDays ||
+		instance.Spec.CertificateKeyAlgorithm != dexcfg.DefaultCertificateKeyAlgorithm ||
+		instance.Spec.AdminGroup != dexcfg.DefaultAdminGroup {
+		t.Fatalf("unexpected defaults: %+v", instance.Spec)
+	}
+
+	// defaults must not be set again
+	if SetDexConfigurationDefaults(instance) {
+		t.Fatalf("defaults set twice")
+	}
+
+	// user-provided values must be preserved (including 0 replicas)
+	replicas := int32(0)
+	instance = &kubicv1beta1.DexConfiguration{
+		Spec: kubicv1beta1.DexConfigurationSpec{NodePort: 31000, Replicas: &replicas},
+	}
+	SetDexConfigurationDefaults(instance)
+	if instance.Spec.NodePort != 31000 || *instance.Spec.Replicas != 0 {
+		t.Fatalf("user values overwritten: %+v", instance.Spec)
+	}
+
+	// only the main instance gets the default NodePort and admin group
+	instance = &kubicv1beta1.DexConfiguration{}
+	instance.SetName("tenant-a")
+	SetDexConfigurationDefaults(instance)
+	if instance.Spec.NodePort != 0 {
+		t.Fatalf("default NodePort set in instance '%s': %d", instance.GetName(), instance.Spec.NodePort)
+	}
+	if len(instance.Spec.AdminGroup) > 0 {
+		t.Fatalf("default admin group set in instance '%s': %s", instance.GetName(), instance.Spec.AdminGroup)
+	}
+
+	// and only when it is exposed with a NodePort
+	instance = &kubicv1beta1.DexConfiguration{
+		Spec: kubicv1beta1.DexConfigurationSpec{
+			Expose: kubicv1beta1.DexExposeSpec{Mode: kubicv1beta1.DexExposeLoadBalancer},
+		},
+	}
+	instance.SetName(dexcfg.DefaultConfigurationName)
+	SetDexConfigurationDefaults(instance)
+	if instance.Spec.NodePort != 0 {
+		t.Fatalf("default NodePort set in %s mode: %d", instance.Spec.Expose.Mode, instance.Spec.NodePort)
+	}
+}
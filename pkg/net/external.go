@@ -20,24 +20,105 @@ package net
 import (
 	"fmt"
 	"net"
+	"sort"
 
 	kubicutil "github.com/kubic-project/dex-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilnet "k8s.io/apimachinery/pkg/util/net"
+	clientset "k8s.io/client-go/kubernetes"
 )
 
 const (
 	defaultDNSDomain = "cluster.local"
+
+	// the Endpoints (in the "default" namespace) with the addresses advertised by the API server
+	apiServerEndpointsName = "kubernetes"
 )
 
-// GetPublicAPIAddress gets a DNS name (or IP address)
-// that can be used for reaching the API server
-// TODO: fix this method: it will not work on containers
-func GetPublicAPIAddress() (string, error) {
-	localIP, err := utilnet.ChooseHostInterface()
+// GetPublicAPIAddress discovers an address (of a node in the cluster) that can be used
+// for reaching the API server (and any NodePort) from outside the cluster.
+// See ChoosePublicAddress for the order of preference.
+func GetPublicAPIAddress(cli clientset.Interface) (string, error) {
+	endpoints, err := cli.CoreV1().Endpoints(metav1.NamespaceDefault).Get(apiServerEndpointsName, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "", err
+	}
+	nodes, err := cli.CoreV1().Nodes().List(metav1.ListOptions{})
 	if err != nil {
 		return "", err
 	}
-	return localIP.String(), nil
+	return ChoosePublicAddress(endpoints, nodes.Items)
+}
+
+// ChoosePublicAddress chooses a public address, from the `kubernetes` Endpoints
+// (the addresses advertised by the API server) and the `nodes` in the cluster,
+// in this order of preference:
+// 1. the ExternalIP of a node where the API server runs
+// 2. an address advertised by the API server
+// 3. the ExternalIP of a node
+// 4. the InternalIP of a node
+// Nodes are considered in order of name, so the same address is always chosen.
+func ChoosePublicAddress(endpoints *corev1.Endpoints, nodes []corev1.Node) (string, error) {
+	sorted := make([]corev1.Node, len(nodes))
+	copy(sorted, nodes)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].GetName() < sorted[j].GetName() })
+
+	advertised := []string{}
+	if endpoints != nil {
+		for _, subset := range endpoints.Subsets {
+			for _, address := range subset.Addresses {
+				advertised = append(advertised, address.IP)
+			}
+		}
+	}
+	sort.Strings(advertised)
+
+	// the ExternalIP of a node where the API server runs
+	for _, node := range sorted {
+		if isAPIServerNode(node, advertised) {
+			if address := getNodeAddress(node, corev1.NodeExternalIP); len(address) > 0 {
+				return address, nil
+			}
+		}
+	}
+
+	if len(advertised) > 0 {
+		return advertised[0], nil
+	}
+
+	for _, addressType := range []corev1.NodeAddressType{corev1.NodeExternalIP, corev1.NodeInternalIP} {
+		for _, node := range sorted {
+			if address := getNodeAddress(node, addressType); len(address) > 0 {
+				return address, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("no public address found in the '%s' Endpoints or in the nodes", apiServerEndpointsName)
+}
+
+// isAPIServerNode returns true if any of the addresses of the node is `advertised` by the API server
+func isAPIServerNode(node corev1.Node, advertised []string) bool {
+	for _, address := range node.Status.Addresses {
+		for _, a := range advertised {
+			if address.Address == a {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getNodeAddress returns the first address of the given type for a node
+func getNodeAddress(node corev1.Node, addressType corev1.NodeAddressType) string {
+	for _, address := range node.Status.Addresses {
+		if address.Type == addressType && len(address.Address) > 0 {
+			return address.Address
+		}
+	}
+	return ""
 }
 
 // GetServiceDNSName gets a FQDN DNS name in ther internal network for `name`
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package net
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestChoosePublicAddress(t *testing.T) {
+	newNode := func(name string, internal, external string) corev1.Node {
+		node := corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if len(internal) > 0 {
+			node.Status.Addresses = append(node.Status.Addresses, corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: internal})
+		}
+		if len(external) > 0 {
+			node.Status.Addresses = append(node.Status.Addresses, corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: external})
+		}
+		return node
+	}
+	newEndpoints := func(addresses ...string) *corev1.Endpoints {
+		subset := corev1.EndpointSubset{}
+		for _, a := range addresses {
+			subset.Addresses = append(subset.Addresses, corev1.EndpointAddress{IP: a})
+		}
+		return &corev1.Endpoints{Subsets: []corev1.EndpointSubset{subset}}
+	}
+
+	tests := []struct {
+		name      string
+		endpoints *corev1.Endpoints
+		nodes     []corev1.Node
+		expected  string
+	}{
+		{
+			name:      "external IP of the API server node",
+			endpoints: newEndpoints("10.0.0.2"),
+			nodes: []corev1.Node{
+				newNode("a", "10.0.0.1", "1.1.1.1"),
+				newNode("b", "10.0.0.2", "2.2.2.2"),
+			},
+			expected: "2.2.2.2",
+		},
+		{
+			name:      "advertised address",
+			endpoints: newEndpoints("10.0.0.3"),
+			nodes: []corev1.Node{
+				newNode("a", "10.0.0.1", "1.1.1.1"),
+			},
+			expected: "10.0.0.3",
+		},
+		{
+			name:      "external IP of a node",
+			endpoints: nil,
+			nodes: []corev1.Node{
+				newNode("b", "10.0.0.2", "2.2.2.2"),
+				newNode("a", "10.0.0.1", ""),
+				newNode("c", "10.0.0.3", "3.3.3.3"),
+			},
+			expected: "2.2.2.2",
+		},
+		{
+			name:      "internal IP of a node",
+			endpoints: &corev1.Endpoints{},
+			nodes: []corev1.Node{
+				newNode("b", "10.0.0.2", ""),
+				newNode("a", "10.0.0.1", ""),
+			},
+			expected: "10.0.0.1",
+		},
+	}
+
+	for _, test := range tests {
+		address, err := ChoosePublicAddress(test.endpoints, test.nodes)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", test.name, err)
+		}
+		if address != test.expected {
+			t.Fatalf("%s: got %q, expected %q", test.name, address, test.expected)
+		}
+	}
+
+	if _, err := ChoosePublicAddress(nil, nil); err == nil {
+		t.Fatalf("no error when there are no addresses")
+	}
+}
@@ -57,3 +57,13 @@ func RemoveDuplicates(in []string) []string {
 
 	return res
 }
+
+// ContainsString returns true if `s` is in the slice
+func ContainsString(slice []string, s string) bool {
+	for _, item := range slice {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
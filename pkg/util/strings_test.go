@@ -47,3 +47,16 @@ func TestNewSharedPassword(t *testing.T) {
 	}
 
 }
+
+func TestContainsString(t *testing.T) {
+	slice := []string{"aaa", "bbb", "ccc"}
+	if !ContainsString(slice, "bbb") {
+		t.Fatalf("'bbb' not found in %+v", slice)
+	}
+	if ContainsString(slice, "bb") {
+		t.Fatalf("'bb' found in %+v", slice)
+	}
+	if ContainsString(nil, "aaa") {
+		t.Fatalf("'aaa' found in an empty slice")
+	}
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package validation
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
+)
+
+// ListConnectors returns all the connectors (of any kind) in the cluster
+func ListConnectors(cli client.Client) ([]runtime.Object, error) {
+	lists := []runtime.Object{
+		&kubicv1beta1.LDAPConnectorList{},
+		&kubicv1beta1.GitHubConnectorList{},
+		&kubicv1beta1.OIDCConnectorList{},
+		&kubicv1beta1.SAMLConnectorList{},
+		&kubicv1beta1.GitLabConnectorList{},
+		&kubicv1beta1.BitbucketCloudConnectorList{},
+		&kubicv1beta1.MicrosoftConnectorList{},
+		&kubicv1beta1.GoogleConnectorList{},
+	}
+
+	res := []runtime.Object{}
+	for _, list := range lists {
+		if err := cli.List(context.TODO(), &client.ListOptions{}, list); err != nil {
+			return nil, err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, items...)
+	}
+	return res, nil
+}
+
+// IsSelectingConnector returns true if a DexConfiguration selects a connector with the given labels
+// (a DexConfiguration without a connectorSelector selects all the connectors)
+func IsSelectingConnector(instance *kubicv1beta1.DexConfiguration, connectorLabels map[string]string) bool {
+	if instance.Spec.ConnectorSelector == nil {
+		return true
+	}
+	selector, err := metav1.LabelSelectorAsSelector(instance.Spec.ConnectorSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(connectorLabels))
+}
+
+// FilterConnectorsSharingInstances returns the connectors in `all` that are used together
+// with `obj` in some DexConfiguration in `instances`. When `obj` is not selected by any
+// instance, all the connectors are returned, as a new instance could select all of them.
+func FilterConnectorsSharingInstances(obj runtime.Object, all []runtime.Object, instances []kubicv1beta1.DexConfiguration) []runtime.Object {
+	objMeta, ok := obj.(metav1.Object)
+	if !ok {
+		return all
+	}
+
+	selecting := []*kubicv1beta1.DexConfiguration{}
+	for i := range instances {
+		if IsSelectingConnector(&instances[i], objMeta.GetLabels()) {
+			selecting = append(selecting, &instances[i])
+		}
+	}
+	if len(selecting) == 0 {
+		return all
+	}
+
+	res := []runtime.Object{}
+	for _, other := range all {
+		otherMeta, ok := other.(metav1.Object)
+		if !ok {
+			continue
+		}
+		for _, instance := range selecting {
+			if IsSelectingConnector(instance, otherMeta.GetLabels()) {
+				res = append(res, other)
+				break
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,430 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package validation
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
+	"github.com/kubic-project/dex-operator/pkg/crypto"
+	"github.com/kubic-project/dex-operator/pkg/util"
+)
+
+const (
+	// MinNodePort is the lowest port in the default NodePorts range
+	MinNodePort = 30000
+
+	// MaxNodePort is the highest port in the default NodePorts range
+	MaxNodePort = 32767
+
+	// OutOfBandRedirectURL is the redirect URL used by clients without a web server
+	OutOfBandRedirectURL = "urn:ietf:wg:oauth:2.0:oob"
+
+	// MaxNameLength is the maximum length of the name of a DexConfiguration
+	// (the name is used in the names of all the objects created for the instance)
+	MaxNameLength = 40
+)
+
+// ValidateDexConfiguration validates a DexConfiguration
+func ValidateDexConfiguration(instance *kubicv1beta1.DexConfiguration) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	namePath := field.NewPath("metadata", "name")
+	for _, msg := range utilvalidation.IsDNS1123Label(instance.GetName()) {
+		allErrs = append(allErrs, field.Invalid(namePath, instance.GetName(), msg))
+	}
+	if len(instance.GetName()) > MaxNameLength {
+		allErrs = append(allErrs, field.TooLong(namePath, instance.GetName(), MaxNameLength))
+	}
+
+	specPath := field.NewPath("spec")
+	spec := instance.Spec
+
+	if spec.ConnectorSelector != nil {
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(spec.ConnectorSelector, specPath.Child("connectorSelector"))...)
+	}
+
+	if len(spec.Namespace) > 0 {
+		for _, msg := range utilvalidation.IsDNS1123Label(spec.Namespace) {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("namespace"), spec.Namespace, msg))
+		}
+	}
+
+	// the Deployment cannot mount a certificate from a different namespace
+	if len(spec.Certificate.Namespace) > 0 && len(spec.Namespace) > 0 && spec.Certificate.Namespace != spec.Namespace {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("certificate", "namespace"), spec.Certificate.Namespace,
+			fmt.Sprintf("must be the namespace where Dex is run (%q)", spec.Namespace)))
+	}
+
+	if spec.NodePort != 0 && (spec.NodePort < MinNodePort || spec.NodePort > MaxNodePort) {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("nodePort"), spec.NodePort,
+			fmt.Sprintf("must be in the range %d-%d", MinNodePort, MaxNodePort)))
+	}
+
+	if len(spec.Issuer) > 0 {
+		allErrs = append(allErrs, ValidateIssuerURL(spec.Issuer, specPath.Child("issuer"))...)
+	}
+
+	allErrs = append(allErrs, ValidateDexExpose(spec.Expose, specPath.Child("expose"))...)
+	allErrs = append(allErrs, ValidateDexCertificateMode(spec, specPath)...)
+
+	if spec.Replicas != nil && *spec.Replicas < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("replicas"), *spec.Replicas, "must be greater than or equal to 0"))
+	}
+
+	if spec.ProgressDeadlineSeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("progressDeadlineSeconds"), spec.ProgressDeadlineSeconds, "must be greater than or equal to 0"))
+	}
+
+	if spec.RevisionHistoryLimit < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("revisionHistoryLimit"), spec.RevisionHistoryLimit, "must be greater than or equal to 0"))
+	}
+
+	if spec.CertificateRenewBeforeDays < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("certificateRenewBeforeDays"), spec.CertificateRenewBeforeDays, "must be greater than or equal to 0"))
+	}
+
+	if len(spec.CertificateKeyAlgorithm) > 0 && !util.ContainsString(crypto.SupportedKeyAlgorithms, spec.CertificateKeyAlgorithm) {
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("certificateKeyAlgorithm"), spec.CertificateKeyAlgorithm, crypto.SupportedKeyAlgorithms))
+	}
+
+	// signer names are like "example.com/my-signer"
+	if len(spec.CertificateSignerName) > 0 {
+		parts := strings.SplitN(spec.CertificateSignerName, "/", 2)
+		if len(parts) != 2 || len(parts[1]) == 0 || len(utilvalidation.IsDNS1123Subdomain(parts[0])) > 0 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("certificateSignerName"), spec.CertificateSignerName, "must be a domain name followed by a path, like 'example.com/my-signer'"))
+		}
+	}
+
+	switch spec.ConfigStorage {
+	case "", "ConfigMap", "Secret":
+	default:
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("configStorage"), spec.ConfigStorage, []string{"ConfigMap", "Secret"}))
+	}
+
+	names := map[string]bool{}
+	for i, sc := range spec.StaticClients {
+		scPath := specPath.Child("staticClients").Index(i)
+		if len(sc.Name) == 0 {
+			allErrs = append(allErrs, field.Required(scPath.Child("name"), ""))
+		} else if names[sc.Name] {
+			allErrs = append(allErrs, field.Duplicate(scPath.Child("name"), sc.Name))
+		}
+		names[sc.Name] = true
+
+		for j, u := range sc.RedirectURLs {
+			allErrs = append(allErrs, ValidateRedirectURL(u, scPath.Child("redirectURLs").Index(j))...)
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateDexExpose validates the way Dex is exposed
+func ValidateDexExpose(expose kubicv1beta1.DexExposeSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	switch expose.Mode {
+	case "", kubicv1beta1.DexExposeNodePort, kubicv1beta1.DexExposeLoadBalancer:
+	case kubicv1beta1.DexExposeIngress:
+		hostPath := fldPath.Child("ingress", "host")
+		if len(expose.Ingress.Host) == 0 {
+			allErrs = append(allErrs, field.Required(hostPath, "required in the Ingress mode"))
+		} else {
+			for _, msg := range utilvalidation.IsDNS1123Subdomain(expose.Ingress.Host) {
+				allErrs = append(allErrs, field.Invalid(hostPath, expose.Ingress.Host, msg))
+			}
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("mode"), expose.Mode, []string{
+			string(kubicv1beta1.DexExposeNodePort),
+			string(kubicv1beta1.DexExposeLoadBalancer),
+			string(kubicv1beta1.DexExposeIngress)}))
+	}
+
+	if len(expose.LoadBalancerIP) > 0 && net.ParseIP(expose.LoadBalancerIP) == nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("loadBalancerIP"), expose.LoadBalancerIP, "must be a valid IP address"))
+	}
+
+	return allErrs
+}
+
+// ValidateDexCertificateMode checks the way the certificate is obtained
+func ValidateDexCertificateMode(spec kubicv1beta1.DexConfigurationSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	switch spec.CertificateMode {
+	case "", kubicv1beta1.DexCertificateCSR:
+	case kubicv1beta1.DexCertificateCertManager:
+		if len(spec.Certificate.Name) > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("certificateMode"), spec.CertificateMode,
+				"a certificate cannot be specified in the CertManager mode"))
+		}
+
+		certManagerPath := fldPath.Child("certManager")
+		issuerRef := spec.CertManager.IssuerRef
+		if len(issuerRef.Name) == 0 {
+			allErrs = append(allErrs, field.Required(certManagerPath.Child("issuerRef", "name"), "required in the CertManager mode"))
+		}
+		switch issuerRef.Kind {
+		case "", "Issuer", "ClusterIssuer":
+		default:
+			if len(issuerRef.Group) == 0 {
+				allErrs = append(allErrs, field.NotSupported(certManagerPath.Child("issuerRef", "kind"), issuerRef.Kind,
+					[]string{"Issuer", "ClusterIssuer"}))
+			}
+		}
+
+		duration, renewBefore := spec.CertManager.Duration, spec.CertManager.RenewBefore
+		if duration != nil && duration.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(certManagerPath.Child("duration"), duration.Duration.String(), "must be greater than 0"))
+		}
+		if renewBefore != nil && renewBefore.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(certManagerPath.Child("renewBefore"), renewBefore.Duration.String(), "must be greater than 0"))
+		}
+		if duration != nil && renewBefore != nil && renewBefore.Duration >= duration.Duration {
+			allErrs = append(allErrs, field.Invalid(certManagerPath.Child("renewBefore"), renewBefore.Duration.String(), "must be shorter than the duration"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("certificateMode"), spec.CertificateMode, []string{
+			string(kubicv1beta1.DexCertificateCSR),
+			string(kubicv1beta1.DexCertificateCertManager)}))
+	}
+
+	return allErrs
+}
+
+// ValidateDexConfigurationConflicts checks that a DexConfiguration does not conflict
+// with any of the (older) DexConfigurations in `all`
+func ValidateDexConfigurationConflicts(instance *kubicv1beta1.DexConfiguration, all []kubicv1beta1.DexConfiguration) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for _, other := range all {
+		if other.GetName() == instance.GetName() || !isOlder(&other, instance) {
+			continue
+		}
+		if usesNodePort(instance) && usesNodePort(&other) && other.Spec.NodePort == instance.Spec.NodePort {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "nodePort"), instance.Spec.NodePort,
+				fmt.Sprintf("already used by DexConfiguration '%s'", other.GetName())))
+		}
+	}
+	return allErrs
+}
+
+// usesNodePort returns true if the DexConfiguration is exposed with a NodePort
+func usesNodePort(instance *kubicv1beta1.DexConfiguration) bool {
+	mode := instance.Spec.Expose.Mode
+	return instance.Spec.NodePort != 0 && (mode == "" || mode == kubicv1beta1.DexExposeNodePort)
+}
+
+// isOlder returns true if `a` has been created before `b`
+// (objects that have not been created yet are always newer)
+func isOlder(a, b *kubicv1beta1.DexConfiguration) bool {
+	ta, tb := a.GetCreationTimestamp(), b.GetCreationTimestamp()
+	switch {
+	case tb.IsZero():
+		return true
+	case ta.IsZero():
+		return false
+	case ta.Equal(&tb):
+		return a.GetName() < b.GetName()
+	}
+	return ta.Before(&tb)
+}
+
+// ValidateRedirectURL checks that `u` is a valid redirect URL for a client:
+// an absolute http(s) URL or the "out of band" URN
+func ValidateRedirectURL(u string, fldPath *field.Path) field.ErrorList {
+	if u == OutOfBandRedirectURL {
+		return field.ErrorList{}
+	}
+	return ValidateURL(u, fldPath)
+}
+
+// ValidateURL checks that `u` is an absolute http(s) URL
+func ValidateURL(u string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return append(allErrs, field.Invalid(fldPath, u, err.Error()))
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		allErrs = append(allErrs, field.Invalid(fldPath, u, "must be a http or https URL"))
+	} else if len(parsed.Host) == 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath, u, "must be an absolute URL"))
+	}
+	return allErrs
+}
+
+// ValidateIssuerURL checks that `u` can be used as the Dex issuer: an absolute
+// https URL (maybe with a path) without query or fragment
+func ValidateIssuerURL(u string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return append(allErrs, field.Invalid(fldPath, u, err.Error()))
+	}
+	if parsed.Scheme != "https" {
+		allErrs = append(allErrs, field.Invalid(fldPath, u, "must be a https URL"))
+	} else if len(parsed.Hostname()) == 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath, u, "must be an absolute URL"))
+	}
+	if len(parsed.RawQuery) > 0 || len(parsed.Fragment) > 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath, u, "must not have a query or a fragment"))
+	}
+	return allErrs
+}
+
+// ValidateConnector validates any of the connectors
+func ValidateConnector(obj runtime.Object) field.ErrorList {
+	allErrs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+
+	id, found := GetConnectorID(obj)
+	if !found {
+		return append(allErrs, field.InternalError(specPath, fmt.Errorf("%T is not a connector", obj)))
+	}
+	if len(id) == 0 {
+		allErrs = append(allErrs, field.Required(specPath.Child("id"), ""))
+	}
+
+	// some common checks for OAuth-based connectors
+	validateOAuth := func(clientID string, secretRef kubicv1beta1.SecretKeyReference, redirectURI string) {
+		if len(clientID) == 0 {
+			allErrs = append(allErrs, field.Required(specPath.Child("clientID"), ""))
+		}
+		if len(secretRef.Name) == 0 {
+			allErrs = append(allErrs, field.Required(specPath.Child("clientSecretRef", "name"), ""))
+		}
+		if len(redirectURI) > 0 {
+			allErrs = append(allErrs, ValidateURL(redirectURI, specPath.Child("redirectURI"))...)
+		}
+	}
+
+	switch c := obj.(type) {
+	case *kubicv1beta1.LDAPConnector:
+		if len(c.Spec.Server) == 0 {
+			allErrs = append(allErrs, field.Required(specPath.Child("server"), ""))
+		}
+		if len(c.Spec.User.EmailAttr) == 0 {
+			allErrs = append(allErrs, field.Required(specPath.Child("user", "emailAttr"), ""))
+		}
+	case *kubicv1beta1.GitHubConnector:
+		validateOAuth(c.Spec.ClientID, c.Spec.ClientSecretRef, c.Spec.RedirectURI)
+	case *kubicv1beta1.OIDCConnector:
+		validateOAuth(c.Spec.ClientID, c.Spec.ClientSecretRef, c.Spec.RedirectURI)
+		if len(c.Spec.Issuer) == 0 {
+			allErrs = append(allErrs, field.Required(specPath.Child("issuer"), ""))
+		} else {
+			allErrs = append(allErrs, ValidateURL(c.Spec.Issuer, specPath.Child("issuer"))...)
+		}
+	case *kubicv1beta1.SAMLConnector:
+		if len(c.Spec.SSOURL) == 0 {
+			allErrs = append(allErrs, field.Required(specPath.Child("ssoURL"), ""))
+		} else {
+			allErrs = append(allErrs, ValidateURL(c.Spec.SSOURL, specPath.Child("ssoURL"))...)
+		}
+		if len(c.Spec.RedirectURI) > 0 {
+			allErrs = append(allErrs, ValidateURL(c.Spec.RedirectURI, specPath.Child("redirectURI"))...)
+		}
+		if len(c.Spec.UsernameAttr) == 0 {
+			allErrs = append(allErrs, field.Required(specPath.Child("usernameAttr"), ""))
+		}
+		if len(c.Spec.EmailAttr) == 0 {
+			allErrs = append(allErrs, field.Required(specPath.Child("emailAttr"), ""))
+		}
+	case *kubicv1beta1.GitLabConnector:
+		validateOAuth(c.Spec.ClientID, c.Spec.ClientSecretRef, c.Spec.RedirectURI)
+		if len(c.Spec.BaseURL) > 0 {
+			allErrs = append(allErrs, ValidateURL(c.Spec.BaseURL, specPath.Child("baseURL"))...)
+		}
+	case *kubicv1beta1.BitbucketCloudConnector:
+		validateOAuth(c.Spec.ClientID, c.Spec.ClientSecretRef, c.Spec.RedirectURI)
+	case *kubicv1beta1.MicrosoftConnector:
+		validateOAuth(c.Spec.ClientID, c.Spec.ClientSecretRef, c.Spec.RedirectURI)
+	case *kubicv1beta1.GoogleConnector:
+		validateOAuth(c.Spec.ClientID, c.Spec.ClientSecretRef, c.Spec.RedirectURI)
+	}
+
+	return allErrs
+}
+
+// ValidateConnectorIDs checks that the connector `obj` does not use the same ID as
+// any other connector in `all` (that can include `obj` too)
+func ValidateConnectorIDs(obj runtime.Object, all []runtime.Object) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	id, found := GetConnectorID(obj)
+	if !found || len(id) == 0 {
+		return allErrs
+	}
+
+	for _, other := range all {
+		if isSameConnector(obj, other) {
+			continue
+		}
+		if otherID, _ := GetConnectorID(other); otherID == id {
+			allErrs = append(allErrs, field.Duplicate(field.NewPath("spec", "id"), id))
+			break
+		}
+	}
+	return allErrs
+}
+
+// GetConnectorID returns the ID of a connector, or false if the object is not a connector
+func GetConnectorID(obj runtime.Object) (string, bool) {
+	switch c := obj.(type) {
+	case *kubicv1beta1.LDAPConnector:
+		return c.Spec.ID, true
+	case *kubicv1beta1.GitHubConnector:
+		return c.Spec.ID, true
+	case *kubicv1beta1.OIDCConnector:
+		return c.Spec.ID, true
+	case *kubicv1beta1.SAMLConnector:
+		return c.Spec.ID, true
+	case *kubicv1beta1.GitLabConnector:
+		return c.Spec.ID, true
+	case *kubicv1beta1.BitbucketCloudConnector:
+		return c.Spec.ID, true
+	case *kubicv1beta1.MicrosoftConnector:
+		return c.Spec.ID, true
+	case *kubicv1beta1.GoogleConnector:
+		return c.Spec.ID, true
+	}
+	return "", false
+}
+
+// isSameConnector returns true if both objects are the same connector (same type and name)
+func isSameConnector(a, b runtime.Object) bool {
+	if fmt.Sprintf("%T", a) != fmt.Sprintf("%T", b) {
+		return false
+	}
+	metaA, okA := a.(interface{ GetName() string })
+	metaB, okB := b.(interface{ GetName() string })
+	return okA && okB && metaA.GetName() == metaB.GetName()
+}
@@ -0,0 +1,257 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package validation
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
+)
+
+func TestValidateDexConfiguration(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    kubicv1beta1.DexConfigurationSpec
+		numErrs int
+	}{
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{}, 0},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{NodePort: 32000}, 0},
+		{"tenant-a", kubicv1beta1.DexConfigurationSpec{}, 0},
+		{"Tenant.A", kubicv1beta1.DexConfigurationSpec{}, 1},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{
+			ConnectorSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}},
+		}, 0},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{
+			ConnectorSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a b"}},
+		}, 1},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{Namespace: "dex-system"}, 0},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{Namespace: "Dex_System"}, 1},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{Namespace: "dex-system",
+			Certificate: corev1.SecretReference{Name: "dex-cert", Namespace: "dex-system"}}, 0},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{Namespace: "dex-system",
+			Certificate: corev1.SecretReference{Name: "dex-cert", Namespace: "kube-system"}}, 1},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{Issuer: "https://my-company.com/dex"}, 0},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{Issuer: "https://10.0.0.1:8443"}, 0},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{Issuer: "http://my-company.com/dex"}, 1},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{Issuer: "https://my-company.com/dex?a=b"}, 1},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{Issuer: "/dex"}, 1},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{NodePort: 443}, 1},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{Expose: kubicv1beta1.DexExposeSpec{
+			Mode: kubicv1beta1.DexExposeLoadBalancer, LoadBalancerIP: "10.0.0.10"}}, 0},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{Expose: kubicv1beta1.DexExposeSpec{
+			Mode: kubicv1beta1.DexExposeLoadBalancer, LoadBalancerIP: "lb"}}, 1},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{Expose: kubicv1beta1.DexExposeSpec{
+			Mode: kubicv1beta1.DexExposeIngress, Ingress: kubicv1beta1.DexIngressSpec{Host: "dex.my-company.com"}}}, 0},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{Expose: kubicv1beta1.DexExposeSpec{
+			Mode: kubicv1beta1.DexExposeIngress}}, 1},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{Expose: kubicv1beta1.DexExposeSpec{
+			Mode: "HostPort"}}, 1},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{NodePort: 40000}, 1},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{ProgressDeadlineSeconds: -1}, 1},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{RevisionHistoryLimit: -1}, 1},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{CertificateRenewBeforeDays: -1}, 1},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{CertificateKeyAlgorithm: "ECDSA-P384"}, 0},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{CertificateKeyAlgorithm: "RSA-1024"}, 1},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{CertificateSignerName: "example.com/my-signer"}, 0},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{CertificateSignerName: "my-signer"}, 1},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{CertificateMode: "Vault"}, 1},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{
+			CertificateMode: kubicv1beta1.DexCertificateCertManager,
+			CertManager: kubicv1beta1.DexCertManagerSpec{
+				IssuerRef:   kubicv1beta1.DexCertManagerIssuerRef{Name: "letsencrypt", Kind: "ClusterIssuer"},
+				Duration:    &metav1.Duration{Duration: 90 * 24 * time.Hour},
+				RenewBefore: &metav1.Duration{Duration: 30 * 24 * time.Hour},
+			},
+		}, 0},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{
+			CertificateMode: kubicv1beta1.DexCertificateCertManager,
+			Certificate:     corev1.SecretReference{Name: "dex-cert"},
+			CertManager: kubicv1beta1.DexCertManagerSpec{
+				IssuerRef:   kubicv1beta1.DexCertManagerIssuerRef{Kind: "Vault"},
+				Duration:    &metav1.Duration{Duration: 24 * time.Hour},
+				RenewBefore: &metav1.Duration{Duration: 48 * time.Hour},
+			},
+		}, 4},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{
+			StaticClients: []kubicv1beta1.DexStaticClient{
+				{Name: "cli", RedirectURLs: []string{OutOfBandRedirectURL, "https://velum.my-company.com/oidc/done"}},
+			},
+		}, 0},
+		{"dex-configuration", kubicv1beta1.DexConfigurationSpec{
+			StaticClients: []kubicv1beta1.DexStaticClient{
+				{Name: "cli", RedirectURLs: []string{"velum.my-company.com/oidc/done", "ftp://velum"}},
+				{Name: "cli"},
+			},
+		}, 3},
+	}
+
+	for _, test := range tests {
+		instance := &kubicv1beta1.DexConfiguration{ObjectMeta: metav1.ObjectMeta{Name: test.name}, Spec: test.spec}
+		errs := ValidateDexConfiguration(instance)
+		if len(errs) != test.numErrs {
+			t.Logf("input: %s %+v", test.name, test.spec)
+			t.Logf("-> errors: %s", errs.ToAggregate())
+			t.Fatalf("unexpected number of errors: %d (expected %d)", len(errs), test.numErrs)
+		}
+	}
+}
+
+func TestValidateDexConfigurationConflicts(t *testing.T) {
+	all := []kubicv1beta1.DexConfiguration{
+		{ObjectMeta: metav1.ObjectMeta{Name: "dex-configuration"}, Spec: kubicv1beta1.DexConfigurationSpec{NodePort: 32000}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a"}, Spec: kubicv1beta1.DexConfigurationSpec{NodePort: 32001}},
+	}
+	created := metav1.Now()
+	all[0].CreationTimestamp = created
+	all[1].CreationTimestamp = metav1.NewTime(created.Add(time.Minute))
+
+	tests := []struct {
+		name     string
+		nodePort int
+		numErrs  int
+	}{
+		{"dex-configuration", 32000, 0},
+		{"tenant-a", 32001, 0},
+		{"tenant-b", 32002, 0},
+		{"tenant-b", 32001, 1},
+		{"tenant-a", 32000, 1},
+	}
+
+	// the newest instance is the one in conflict
+	if errs := ValidateDexConfigurationConflicts(&all[0], []kubicv1beta1.DexConfiguration{all[0], all[1], {
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant-b", CreationTimestamp: all[1].CreationTimestamp},
+		Spec:       kubicv1beta1.DexConfigurationSpec{NodePort: 32000},
+	}}); len(errs) > 0 {
+		t.Fatalf("conflict detected in the oldest instance: %s", errs.ToAggregate())
+	}
+
+	for _, test := range tests {
+		instance := &kubicv1beta1.DexConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: test.name},
+			Spec:       kubicv1beta1.DexConfigurationSpec{NodePort: test.nodePort},
+		}
+		errs := ValidateDexConfigurationConflicts(instance, all)
+		if len(errs) != test.numErrs {
+			t.Logf("input: %s %d", test.name, test.nodePort)
+			t.Logf("-> errors: %s", errs.ToAggregate())
+			t.Fatalf("unexpected number of errors: %d (expected %d)", len(errs), test.numErrs)
+		}
+	}
+
+	// the nodePort is not used when Dex is not exposed with a NodePort
+	instance := &kubicv1beta1.DexConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant-b"},
+		Spec: kubicv1beta1.DexConfigurationSpec{NodePort: 32000,
+			Expose: kubicv1beta1.DexExposeSpec{Mode: kubicv1beta1.DexExposeIngress}},
+	}
+	if errs := ValidateDexConfigurationConflicts(instance, all); len(errs) > 0 {
+		t.Fatalf("unexpected conflict: %s", errs.ToAggregate())
+	}
+}
+
+func TestValidateConnector(t *testing.T) {
+	secretRef := kubicv1beta1.SecretKeyReference{Name: "some-secret"}
+
+	tests := []struct {
+		obj     runtime.Object
+		numErrs int
+	}{
+		{&kubicv1beta1.LDAPConnector{Spec: kubicv1beta1.LDAPConnectorSpec{ID: "ldap", Server: "ldap:389",
+			User: kubicv1beta1.LDAPUserSpec{EmailAttr: "mail"}}}, 0},
+		{&kubicv1beta1.LDAPConnector{Spec: kubicv1beta1.LDAPConnectorSpec{ID: "ldap", Server: "ldap:389"}}, 1},
+		{&kubicv1beta1.LDAPConnector{Spec: kubicv1beta1.LDAPConnectorSpec{}}, 3},
+		{&kubicv1beta1.GitHubConnector{Spec: kubicv1beta1.GitHubConnectorSpec{ID: "github", ClientID: "id",
+			ClientSecretRef: secretRef}}, 0},
+		{&kubicv1beta1.GitHubConnector{Spec: kubicv1beta1.GitHubConnectorSpec{ID: "github", ClientID: "id",
+			ClientSecretRef: secretRef, RedirectURI: "/callback"}}, 1},
+		{&kubicv1beta1.OIDCConnector{Spec: kubicv1beta1.OIDCConnectorSpec{ID: "oidc", ClientID: "id",
+			ClientSecretRef: secretRef, Issuer: "https://accounts.google.com"}}, 0},
+		{&kubicv1beta1.OIDCConnector{Spec: kubicv1beta1.OIDCConnectorSpec{ID: "oidc"}}, 3},
+		{&kubicv1beta1.SAMLConnector{Spec: kubicv1beta1.SAMLConnectorSpec{ID: "saml", SSOURL: "https://saml/sso",
+			UsernameAttr: "name", EmailAttr: "email"}}, 0},
+		{&kubicv1beta1.SAMLConnector{Spec: kubicv1beta1.SAMLConnectorSpec{ID: "saml", SSOURL: "saml"}}, 3},
+		{&kubicv1beta1.DexConfiguration{}, 1},
+	}
+
+	for _, test := range tests {
+		errs := ValidateConnector(test.obj)
+		if len(errs) != test.numErrs {
+			t.Logf("input: %+v", test.obj)
+			t.Logf("-> errors: %s", errs.ToAggregate())
+			t.Fatalf("unexpected number of errors: %d (expected %d)", len(errs), test.numErrs)
+		}
+	}
+}
+
+func TestValidateConnectorIDs(t *testing.T) {
+	ldap := &kubicv1beta1.LDAPConnector{ObjectMeta: metav1.ObjectMeta{Name: "ldap"},
+		Spec: kubicv1beta1.LDAPConnectorSpec{ID: "some-id"}}
+	github := &kubicv1beta1.GitHubConnector{ObjectMeta: metav1.ObjectMeta{Name: "github"},
+		Spec: kubicv1beta1.GitHubConnectorSpec{ID: "other-id"}}
+	all := []runtime.Object{ldap, github}
+
+	// the connector itself is not a duplicate
+	if errs := ValidateConnectorIDs(ldap, all); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %s", errs.ToAggregate())
+	}
+
+	// a connector with the same name but a different kind is
+	dup := &kubicv1beta1.GitHubConnector{ObjectMeta: metav1.ObjectMeta{Name: "ldap"},
+		Spec: kubicv1beta1.GitHubConnectorSpec{ID: "some-id"}}
+	if errs := ValidateConnectorIDs(dup, all); len(errs) != 1 {
+		t.Fatalf("duplicate ID not detected")
+	}
+}
+
+func TestFilterConnectorsSharingInstances(t *testing.T) {
+	tenantA := &kubicv1beta1.LDAPConnector{ObjectMeta: metav1.ObjectMeta{Name: "ldap-a",
+		Labels: map[string]string{"tenant": "a"}}, Spec: kubicv1beta1.LDAPConnectorSpec{ID: "ldap"}}
+	tenantB := &kubicv1beta1.LDAPConnector{ObjectMeta: metav1.ObjectMeta{Name: "ldap-b",
+		Labels: map[string]string{"tenant": "b"}}, Spec: kubicv1beta1.LDAPConnectorSpec{ID: "ldap"}}
+	all := []runtime.Object{tenantA, tenantB}
+
+	selecting := func(name, tenant string) kubicv1beta1.DexConfiguration {
+		return kubicv1beta1.DexConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: kubicv1beta1.DexConfigurationSpec{ConnectorSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"tenant": tenant}}},
+		}
+	}
+
+	// connectors in different instances can use the same ID
+	instances := []kubicv1beta1.DexConfiguration{selecting("tenant-a", "a"), selecting("tenant-b", "b")}
+	if errs := ValidateConnectorIDs(tenantA, FilterConnectorsSharingInstances(tenantA, all, instances)); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %s", errs.ToAggregate())
+	}
+
+	// but not when some instance selects both of them
+	instances = append(instances, kubicv1beta1.DexConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "all"}})
+	if errs := ValidateConnectorIDs(tenantA, FilterConnectorsSharingInstances(tenantA, all, instances)); len(errs) != 1 {
+		t.Fatalf("duplicate ID not detected")
+	}
+
+	// connectors not selected by any instance are checked against all the connectors
+	if errs := ValidateConnectorIDs(tenantA, FilterConnectorsSharingInstances(tenantA, all, nil)); len(errs) != 1 {
+		t.Fatalf("duplicate ID not detected")
+	}
+}
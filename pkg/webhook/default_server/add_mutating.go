@@ -0,0 +1,41 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package defaultserver
+
+import (
+	"fmt"
+
+	"github.com/kubic-project/dex-operator/pkg/webhook/default_server/mutating"
+)
+
+func init() {
+	for k, v := range mutating.Builders {
+		_, found := builderMap[k]
+		if found {
+			panic(fmt.Sprintf("conflicting webhook builder names in builder map: %v", k))
+		}
+		builderMap[k] = v
+	}
+	for k, v := range mutating.HandlerMap {
+		_, found := HandlerMap[k]
+		if found {
+			panic(fmt.Sprintf("conflicting webhook builder names in handler map: %v", k))
+		}
+		HandlerMap[k] = v
+	}
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package mutating
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/golang/glog"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+
+	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
+	"github.com/kubic-project/dex-operator/pkg/defaults"
+)
+
+// DexConfigurationCreateUpdateHandler sets the default values in DexConfigurations
+type DexConfigurationCreateUpdateHandler struct {
+	// Decoder decodes objects
+	Decoder types.Decoder
+}
+
+var _ admission.Handler = &DexConfigurationCreateUpdateHandler{}
+
+// Handle handles admission requests.
+func (h *DexConfigurationCreateUpdateHandler) Handle(ctx context.Context, req types.Request) types.Response {
+	obj := &kubicv1beta1.DexConfiguration{}
+
+	if err := h.Decoder.Decode(req, obj); err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+
+	defaulted := obj.DeepCopy()
+	if defaults.SetDexConfigurationDefaults(defaulted) {
+		glog.V(3).Infof("[kubic] setting default values in DexConfiguration '%s'", obj.GetName())
+	}
+	return admission.PatchResponse(obj, defaulted)
+}
+
+var _ inject.Decoder = &DexConfigurationCreateUpdateHandler{}
+
+// InjectDecoder injects the decoder into the DexConfigurationCreateUpdateHandler
+func (h *DexConfigurationCreateUpdateHandler) InjectDecoder(d types.Decoder) error {
+	h.Decoder = d
+	return nil
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package defaultserver
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/golang/glog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/builder"
+
+	dexcfg "github.com/kubic-project/dex-operator/pkg/config"
+)
+
+const (
+	// the directory where the certificates for the webhook server are generated
+	webhookCertDir = "/tmp/cert"
+)
+
+var (
+	builderMap = map[string]*builder.WebhookBuilder{}
+
+	// HandlerMap contains all the admission webhook handlers
+	HandlerMap = map[string][]admission.Handler{}
+
+	// the labels of the pods running the operator
+	webhookSelectors = map[string]string{
+		"control-plane": "dex-operator-manager",
+	}
+)
+
+// Add adds the webhook server to the manager. On startup, the server creates (or updates)
+// the webhook configurations and the Service for reaching the operator.
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations;validatingwebhookconfigurations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+func Add(mgr manager.Manager) error {
+	if !dexcfg.WebhooksEnabled {
+		glog.V(1).Infof("[kubic] webhooks are disabled")
+		return nil
+	}
+
+	// the namespace where the operator is running
+	namespace := os.Getenv("POD_NAMESPACE")
+	if len(namespace) == 0 {
+		namespace = metav1.NamespaceSystem
+	}
+
+	svr, err := webhook.NewServer(fmt.Sprintf("%s-webhook-server", dexcfg.DefaultPrefix), mgr, webhook.ServerOptions{
+		Port:    int32(dexcfg.DefaultWebhookPort),
+		CertDir: webhookCertDir,
+		BootstrapOptions: &webhook.BootstrapOptions{
+			MutatingWebhookConfigName:   fmt.Sprintf("%s-mutating-webhook-configuration", dexcfg.DefaultPrefix),
+			ValidatingWebhookConfigName: fmt.Sprintf("%s-validating-webhook-configuration", dexcfg.DefaultPrefix),
+			Service: &webhook.Service{
+				Namespace: namespace,
+				Name:      fmt.Sprintf("%s-webhook-server", dexcfg.DefaultPrefix),
+				Selectors: webhookSelectors,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	var webhooks []webhook.Webhook
+	for name, b := range builderMap {
+		handlers, ok := HandlerMap[name]
+		if !ok {
+			glog.V(1).Infof("[kubic] no handlers found for webhook %s", name)
+			handlers = []admission.Handler{}
+		}
+		wh, err := b.Handlers(handlers...).WithManager(mgr).Build()
+		if err != nil {
+			return err
+		}
+		glog.V(3).Infof("[kubic] registering webhook %s", name)
+		webhooks = append(webhooks, wh)
+	}
+
+	return svr.Register(webhooks...)
+}
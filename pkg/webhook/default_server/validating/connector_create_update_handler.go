@@ -0,0 +1,102 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package validating
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/golang/glog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+
+	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
+	"github.com/kubic-project/dex-operator/pkg/validation"
+)
+
+// ConnectorCreateUpdateHandler validates connectors (of any kind)
+type ConnectorCreateUpdateHandler struct {
+	// creates a new (empty) connector of the kind validated by this handler
+	newObj func() runtime.Object
+
+	// Client is used for looking for other connectors
+	Client client.Client
+
+	// Decoder decodes objects
+	Decoder types.Decoder
+}
+
+// NewConnectorCreateUpdateHandler creates a handler for connectors created by `newObj`
+func NewConnectorCreateUpdateHandler(newObj func() runtime.Object) *ConnectorCreateUpdateHandler {
+	return &ConnectorCreateUpdateHandler{newObj: newObj}
+}
+
+var _ admission.Handler = &ConnectorCreateUpdateHandler{}
+
+// Handle handles admission requests.
+func (h *ConnectorCreateUpdateHandler) Handle(ctx context.Context, req types.Request) types.Response {
+	obj := h.newObj()
+
+	if err := h.Decoder.Decode(req, obj); err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+	if skipValidation(req, obj.(metav1.Object)) {
+		return admission.ValidationResponse(true, "")
+	}
+
+	errs := validation.ValidateConnector(obj)
+
+	// check the ID is not used by any other connector used in the same DexConfigurations
+	all, err := validation.ListConnectors(h.Client)
+	if err != nil {
+		return admission.ErrorResponse(http.StatusInternalServerError, err)
+	}
+	instances := &kubicv1beta1.DexConfigurationList{}
+	if err := h.Client.List(ctx, &client.ListOptions{}, instances); err != nil {
+		return admission.ErrorResponse(http.StatusInternalServerError, err)
+	}
+	all = validation.FilterConnectorsSharingInstances(obj, all, instances.Items)
+	errs = append(errs, validation.ValidateConnectorIDs(obj, all)...)
+
+	if len(errs) > 0 {
+		glog.V(3).Infof("[kubic] %s '%s' rejected: %s", req.AdmissionRequest.Kind.Kind,
+			obj.(metav1.Object).GetName(), errs.ToAggregate())
+		return admission.ValidationResponse(false, errs.ToAggregate().Error())
+	}
+	return admission.ValidationResponse(true, "")
+}
+
+var _ inject.Client = &ConnectorCreateUpdateHandler{}
+
+// InjectClient injects the client into the ConnectorCreateUpdateHandler
+func (h *ConnectorCreateUpdateHandler) InjectClient(c client.Client) error {
+	h.Client = c
+	return nil
+}
+
+var _ inject.Decoder = &ConnectorCreateUpdateHandler{}
+
+// InjectDecoder injects the decoder into the ConnectorCreateUpdateHandler
+func (h *ConnectorCreateUpdateHandler) InjectDecoder(d types.Decoder) error {
+	h.Decoder = d
+	return nil
+}
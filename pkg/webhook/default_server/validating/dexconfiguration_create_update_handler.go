@@ -0,0 +1,86 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package validating
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/golang/glog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+
+	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
+	"github.com/kubic-project/dex-operator/pkg/validation"
+)
+
+// DexConfigurationCreateUpdateHandler validates DexConfigurations
+type DexConfigurationCreateUpdateHandler struct {
+	// Client is used for looking for other DexConfigurations
+	Client client.Client
+
+	// Decoder decodes objects
+	Decoder types.Decoder
+}
+
+var _ admission.Handler = &DexConfigurationCreateUpdateHandler{}
+
+// Handle handles admission requests.
+func (h *DexConfigurationCreateUpdateHandler) Handle(ctx context.Context, req types.Request) types.Response {
+	obj := &kubicv1beta1.DexConfiguration{}
+
+	if err := h.Decoder.Decode(req, obj); err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+	if skipValidation(req, obj) {
+		return admission.ValidationResponse(true, "")
+	}
+
+	errs := validation.ValidateDexConfiguration(obj)
+
+	// check there are no conflicts with other instances
+	all := &kubicv1beta1.DexConfigurationList{}
+	if err := h.Client.List(ctx, &client.ListOptions{}, all); err != nil {
+		return admission.ErrorResponse(http.StatusInternalServerError, err)
+	}
+	errs = append(errs, validation.ValidateDexConfigurationConflicts(obj, all.Items)...)
+
+	if len(errs) > 0 {
+		glog.V(3).Infof("[kubic] DexConfiguration '%s' rejected: %s", obj.GetName(), errs.ToAggregate())
+		return admission.ValidationResponse(false, errs.ToAggregate().Error())
+	}
+	return admission.ValidationResponse(true, "")
+}
+
+var _ inject.Client = &DexConfigurationCreateUpdateHandler{}
+
+// InjectClient injects the client into the DexConfigurationCreateUpdateHandler
+func (h *DexConfigurationCreateUpdateHandler) InjectClient(c client.Client) error {
+	h.Client = c
+	return nil
+}
+
+var _ inject.Decoder = &DexConfigurationCreateUpdateHandler{}
+
+// InjectDecoder injects the decoder into the DexConfigurationCreateUpdateHandler
+func (h *DexConfigurationCreateUpdateHandler) InjectDecoder(d types.Decoder) error {
+	h.Decoder = d
+	return nil
+}
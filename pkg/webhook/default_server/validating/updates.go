@@ -0,0 +1,52 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package validating
+
+import (
+	"encoding/json"
+	"reflect"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+// skipValidation returns true when the object in the request must be admitted without
+// validating it: objects being deleted (so the controller can remove its finalizers) and
+// updates that change nothing but the metadata (ie, the finalizers) or the status
+func skipValidation(req types.Request, obj metav1.Object) bool {
+	if obj.GetDeletionTimestamp() != nil {
+		return true
+	}
+	if req.AdmissionRequest.Operation != admissionv1beta1.Update {
+		return false
+	}
+
+	var current, old map[string]interface{}
+	if err := json.Unmarshal(req.AdmissionRequest.Object.Raw, &current); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(req.AdmissionRequest.OldObject.Raw, &old); err != nil {
+		return false
+	}
+	for _, m := range []map[string]interface{}{current, old} {
+		delete(m, "metadata")
+		delete(m, "status")
+	}
+	return reflect.DeepEqual(current, old)
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package validating
+
+import (
+	"testing"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+
+	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
+)
+
+func TestSkipValidation(t *testing.T) {
+	now := metav1.Now()
+	old := `{"metadata":{"name":"dex"},"spec":{"names":["dex.example.com"]}}`
+
+	tests := []struct {
+		name      string
+		operation admissionv1beta1.Operation
+		object    string
+		deleting  bool
+		expected  bool
+	}{
+		{"create", admissionv1beta1.Create, old, false, false},
+		{"spec changed", admissionv1beta1.Update, `{"metadata":{"name":"dex"},"spec":{"names":["other.example.com"]}}`, false, false},
+		{"finalizers changed", admissionv1beta1.Update, `{"metadata":{"name":"dex","finalizers":["kubic"]},"spec":{"names":["dex.example.com"]}}`, false, true},
+		{"status changed", admissionv1beta1.Update, `{"metadata":{"name":"dex"},"spec":{"names":["dex.example.com"]},"status":{"issuer":"https://dex"}}`, false, true},
+		{"being deleted", admissionv1beta1.Update, `{"metadata":{"name":"dex"},"spec":{"names":["other.example.com"]}}`, true, true},
+	}
+
+	for _, tt := range tests {
+		req := types.Request{
+			AdmissionRequest: &admissionv1beta1.AdmissionRequest{
+				Operation: tt.operation,
+				Object:    runtime.RawExtension{Raw: []byte(tt.object)},
+				OldObject: runtime.RawExtension{Raw: []byte(old)},
+			},
+		}
+		obj := &kubicv1beta1.DexConfiguration{}
+		if tt.deleting {
+			obj.SetDeletionTimestamp(&now)
+		}
+		if got := skipValidation(req, obj); got != tt.expected {
+			t.Errorf("%s: skipValidation() = %v, expected %v", tt.name, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2018 SUSE LINUX GmbH, Nuernberg, Germany..
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package validating
+
+import (
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/builder"
+
+	kubicv1beta1 "github.com/kubic-project/dex-operator/pkg/apis/kubic/v1beta1"
+)
+
+var (
+	// Builders contain admission webhook builders
+	Builders = map[string]*builder.WebhookBuilder{}
+
+	// HandlerMap contains admission webhook handlers
+	HandlerMap = map[string][]admission.Handler{}
+)
+
+func init() {
+	register("validating-create-update-dexconfiguration", &kubicv1beta1.DexConfiguration{},
+		&DexConfigurationCreateUpdateHandler{})
+
+	register("validating-create-update-ldapconnector", &kubicv1beta1.LDAPConnector{},
+		NewConnectorCreateUpdateHandler(func() runtime.Object { return &kubicv1beta1.LDAPConnector{} }))
+	register("validating-create-update-githubconnector", &kubicv1beta1.GitHubConnector{},
+		NewConnectorCreateUpdateHandler(func() runtime.Object { return &kubicv1beta1.GitHubConnector{} }))
+	register("validating-create-update-oidcconnector", &kubicv1beta1.OIDCConnector{},
+		NewConnectorCreateUpdateHandler(func() runtime.Object { return &kubicv1beta1.OIDCConnector{} }))
+	register("validating-create-update-samlconnector", &kubicv1beta1.SAMLConnector{},
+		NewConnectorCreateUpdateHandler(func() runtime.Object { return &kubicv1beta1.SAMLConnector{} }))
+	register("validating-create-update-gitlabconnector", &kubicv1beta1.GitLabConnector{},
+		NewConnectorCreateUpdateHandler(func() runtime.Object { return &kubicv1beta1.GitLabConnector{} }))
+	register("validating-create-update-bitbucketcloudconnector", &kubicv1beta1.BitbucketCloudConnector{},
+		NewConnectorCreateUpdateHandler(func() runtime.Object { return &kubicv1beta1.BitbucketCloudConnector{} }))
+	register("validating-create-update-microsoftconnector", &kubicv1beta1.MicrosoftConnector{},
+		NewConnectorCreateUpdateHandler(func() runtime.Object { return &kubicv1beta1.MicrosoftConnector{} }))
+	register("validating-create-update-googleconnector", &kubicv1beta1.GoogleConnector{},
+		NewConnectorCreateUpdateHandler(func() runtime.Object { return &kubicv1beta1.GoogleConnector{} }))
+}
+
+// register adds a validating webhook for objects with the same type as `obj`
+func register(name string, obj runtime.Object, handler admission.Handler) {
+	Builders[name] = builder.
+		NewWebhookBuilder().
+		Name(name+".kubic.opensuse.org").
+		Path("/"+name).
+		Validating().
+		Operations(admissionregistrationv1beta1.Create, admissionregistrationv1beta1.Update).
+		FailurePolicy(admissionregistrationv1beta1.Fail).
+		ForType(obj)
+
+	HandlerMap[name] = append(HandlerMap[name], handler)
+}